@@ -0,0 +1,303 @@
+package hx711
+
+import (
+	"fmt"
+	"math"
+	"time"
+)
+
+// Point is a calibration point, a raw reading, adjusted for offset and tare, of a known weight in the
+// calibration unit.
+type Point struct {
+	Raw    int64
+	Weight float64
+}
+
+// CalibrationFit describes the line fitted by FitCalibration and how well it fits its points.
+type CalibrationFit struct {
+	// Slope is calibration units per raw unit, it is the calibration factor.
+	Slope float64
+	// Intercept is the weight of a raw 0, it should be close to 0 as points are relative to offset.
+	Intercept float64
+	// SlopeStdErr and InterceptStdErr are the standard errors of slope and intercept, 0 with only 2 points.
+	SlopeStdErr     float64
+	InterceptStdErr float64
+	// ResidualRMS is the root mean square of the distance, in calibration units, of the points to the line.
+	ResidualRMS float64
+	// Points is the number of points fitted.
+	Points int
+}
+
+// AddCalibrationPoint reads the current load, which should weigh weightInGrams, and keeps it as a point for
+// FitCalibration. Zero the empty scale before adding points, they are taken relative to offset and tare.
+func (d *Device) AddCalibrationPoint(weightInGrams float64) Point {
+	d.opMutex.Lock()
+	defer d.opMutex.Unlock()
+	if d.notInitialized() {
+		return Point{}
+	}
+	p := Point{Raw: d.sample() - d.offset - d.tare, Weight: weightInGrams}
+	d.calibrationPoints = append(d.calibrationPoints, p)
+	return p
+}
+
+// CalibrationPoints returns a copy of the points added with AddCalibrationPoint, to store them and keep adding
+// points to the same calibration after a restart, see LoadCalibrationPoints.
+func (d *Device) CalibrationPoints() []Point {
+	d.opMutex.Lock()
+	defer d.opMutex.Unlock()
+	return append([]Point(nil), d.calibrationPoints...)
+}
+
+// LoadCalibrationPoints replaces the calibration points with a copy of points, ie: the ones returned by
+// CalibrationPoints before a restart. The calibration factor is not changed until FitCalibration is called.
+// Points are relative to offset and tare, so they are only valid with the same zero they were taken with.
+func (d *Device) LoadCalibrationPoints(points []Point) {
+	d.opMutex.Lock()
+	defer d.opMutex.Unlock()
+	d.calibrationPoints = append([]Point(nil), points...)
+}
+
+// FitCalibration fits a line through the points added with AddCalibrationPoint by least squares and sets its
+// slope as calibration factor, the intercept is not applied. At least 2 points are needed, 3 or more to get
+// meaningful errors. The quality of the fit is returned and kept for CalibrationQuality, a high ResidualRMS
+// or standard errors mean a bad calibration run that should be rejected.
+func (d *Device) FitCalibration() (CalibrationFit, error) {
+	d.opMutex.Lock()
+	defer d.opMutex.Unlock()
+	fit, err := fitLine(d.calibrationPoints)
+	if err != nil {
+		return CalibrationFit{}, err
+	}
+	if fit.Slope == 0 {
+		return CalibrationFit{}, fmt.Errorf("fitting calibration: %w", ErrZeroFactor)
+	}
+	d.setCalibrationFactor(fit.Slope)
+	d.calibrationFit = &fit
+	return fit, nil
+}
+
+// CalibrationQuality returns the quality of the last FitCalibration.
+func (d *Device) CalibrationQuality() (CalibrationFit, error) {
+	d.opMutex.Lock()
+	defer d.opMutex.Unlock()
+	if d.calibrationFit == nil {
+		return CalibrationFit{}, fmt.Errorf("no calibration was fitted: %w", ErrNotCalibrated)
+	}
+	return *d.calibrationFit, nil
+}
+
+// LinearityError returns how far from a straight line the calibration points added with AddCalibrationPoint
+// are, the largest distance of a point to the line FitCalibration would fit, as a percentage of full scale,
+// the usual linearity spec of load cells. Full scale is the capacity of the cell when known, see
+// ConfigureFromRating and CalibrateFullScale, or the heaviest point otherwise. At least 2 points are needed, 3 or
+// more to tell anything.
+func (d *Device) LinearityError() (float64, error) {
+	d.opMutex.Lock()
+	defer d.opMutex.Unlock()
+	fit, err := fitLine(d.calibrationPoints)
+	if err != nil {
+		return 0, err
+	}
+	span := d.capacity
+	var worst float64
+	for _, p := range d.calibrationPoints {
+		if d.capacity <= 0 && math.Abs(p.Weight) > span {
+			span = math.Abs(p.Weight)
+		}
+		if deviation := math.Abs(p.Weight - (fit.Slope*float64(p.Raw) + fit.Intercept)); deviation > worst {
+			worst = deviation
+		}
+	}
+	if span == 0 {
+		return 0, fmt.Errorf("linearity of calibration points that all weigh 0: %w", ErrZeroWeight)
+	}
+	return worst / span * 100, nil
+}
+
+// fitLine fits weight = slope * raw + intercept.
+func fitLine(points []Point) (CalibrationFit, error) {
+	n := float64(len(points))
+	if len(points) < 2 {
+		return CalibrationFit{}, fmt.Errorf("at least 2 calibration points are needed, got %d: %w", len(points), ErrTooFewPoints)
+	}
+	var meanX, meanY float64
+	for _, p := range points {
+		meanX += float64(p.Raw)
+		meanY += p.Weight
+	}
+	meanX /= n
+	meanY /= n
+	var sxx, sxy float64
+	for _, p := range points {
+		dx := float64(p.Raw) - meanX
+		sxx += dx * dx
+		sxy += dx * (p.Weight - meanY)
+	}
+	if sxx == 0 {
+		return CalibrationFit{}, fmt.Errorf("calibration points need at least 2 different readings: %w", ErrTooFewPoints)
+	}
+	fit := CalibrationFit{Slope: sxy / sxx, Points: len(points)}
+	fit.Intercept = meanY - fit.Slope*meanX
+	var squares float64
+	for _, p := range points {
+		residual := p.Weight - (fit.Slope*float64(p.Raw) + fit.Intercept)
+		squares += residual * residual
+	}
+	fit.ResidualRMS = math.Sqrt(squares / n)
+	if len(points) > 2 {
+		variance := squares / (n - 2)
+		fit.SlopeStdErr = math.Sqrt(variance / sxx)
+		fit.InterceptStdErr = math.Sqrt(variance * (1/n + meanX*meanX/sxx))
+	}
+	return fit, nil
+}
+
+// fullScale is the code the chip outputs for the top of its input range.
+const fullScale = 1 << 23
+
+// gainFactor returns the amplification of a gain level.
+func gainFactor(g gainLVL) float64 {
+	switch g {
+	case Gain64:
+		return 64
+	case Gain32:
+		return 32
+	}
+	return 128
+}
+
+// ScaleCalibrationForGain adjusts the calibration factor taken at gain from to be used at gain to, a count at
+// a lower gain is worth proportionally more weight, so there is no need to calibrate again after changing gain.
+// Only the ratio of the gains is accounted for, channel B (Gain32) usually has a different cell and should be
+// calibrated on its own, see SetCalibrationFactorForChannel. It returns the new factor, the gain is not changed.
+func (d *Device) ScaleCalibrationForGain(from, to gainLVL) float64 {
+	d.opMutex.Lock()
+	defer d.opMutex.Unlock()
+	d.syncFactor()
+	d.setCalibrationFactor(d.calibrationFactor * gainFactor(from) / gainFactor(to))
+	return d.calibrationFactor
+}
+
+// ConfigureFromRating seeds the calibration from the datasheet of the load cell so readings are roughly right
+// before any physical calibration, it also selects the passed gain and remembers the capacity of the cell.
+// The hx711 input range is ±0.5·AVDD/gain and boards usually excite the cell from AVDD, so excitationV is taken
+// as AVDD too, the math is ratiometric and the actual voltage barely matters as long as that holds.
+// It returns the resulting calibration factor.
+func (d *Device) ConfigureFromRating(capacityGrams float64, sensitivityMvV float64, excitationV float64, gain gainLVL) (float64, error) {
+	d.opMutex.Lock()
+	defer d.opMutex.Unlock()
+	if capacityGrams <= 0 || sensitivityMvV <= 0 || excitationV <= 0 {
+		return 0, fmt.Errorf("capacity, sensitivity and excitation need to be > 0: %w", ErrZeroWeight)
+	}
+	if gain < Gain128 || gain > Gain32 {
+		gain = Gain128
+	}
+	if gain != d.gain {
+		d.gain = gain
+		d.stateChanged()
+	}
+	fullScaleVolts := sensitivityMvV / 1000 * excitationV
+	inputRange := 0.5 * excitationV / gainFactor(gain)
+	counts := fullScaleVolts / inputRange * fullScale
+	d.capacity = capacityGrams
+	d.setCalibrationFactor(capacityGrams / counts)
+	return d.calibrationFactor, nil
+}
+
+// CalibrateFullScale takes the current load as exactly the rated capacity of the cell, in the calibration unit,
+// and calculates the calibration factor from it, this is how cells specified by their full scale output are
+// usually calibrated at the factory. Like Calibrate the load is adjusted for offset and tare, so zero the
+// empty scale first. The capacity is remembered, like ConfigureFromRating does. It returns the factor.
+func (d *Device) CalibrateFullScale(capacityGrams float64) (float64, error) {
+	d.opMutex.Lock()
+	defer d.opMutex.Unlock()
+	if d.notInitialized() {
+		return 0, ErrNotInitialized
+	}
+	if capacityGrams <= 0 {
+		return 0, fmt.Errorf("calibrating at full scale: %w", ErrZeroWeight)
+	}
+	raw := d.sample() - d.offset - d.tare
+	if raw == 0 {
+		return 0, fmt.Errorf("the full scale load reads as 0: %w", ErrZeroFactor)
+	}
+	d.capacity = capacityGrams
+	d.setCalibrationFactor(capacityGrams / float64(raw))
+	return d.calibrationFactor, nil
+}
+
+// OlkalCalFactor returns the calibration as the value setCalFactor of olkal/HX711_ADC takes, to share it with an
+// Arduino on the same hardware. That library divides by its factor, raw units per calibration unit, where we
+// multiply by ours, so it is the inverse. Both work on the same sign extended counts averaged over the samples
+// and subtract the tare offset before scaling, so nothing else needs adjusting. It is 1 when not calibrated.
+func (d *Device) OlkalCalFactor() float64 {
+	d.opMutex.Lock()
+	defer d.opMutex.Unlock()
+	d.syncFactor()
+	return 1 / d.calibrationFactor
+}
+
+// ResetCalibration clears all calibration state, the factor goes back to 1, the device is no longer
+// calibrated and the multi point calibration points and fit are dropped. Offset and tare are kept.
+func (d *Device) ResetCalibration() {
+	d.opMutex.Lock()
+	defer d.opMutex.Unlock()
+	d.resetCalibration()
+}
+
+func (d *Device) resetCalibration() {
+	d.calibrationFactor = 1
+	d.channelBFactor = 0
+	d.ratio = ratio{}
+	d.factorStale = false
+	d.hasCalibration = false
+	d.calibrationTime = time.Time{}
+	d.calibrationPoints = nil
+	d.calibrationFit = nil
+}
+
+// CopyCalibrationFrom copies what belongs to the load cell from other: calibration factors, including the
+// fixed point ratio and channel B's, gain, capacity and the multi point calibration points and fit, so a board
+// can be swapped in the field without calibrating again. Offset and tare depend on the installation and are
+// left alone, zero the scale after the swap.
+func (d *Device) CopyCalibrationFrom(other *Device) {
+	if other == d {
+		return
+	}
+	// copy under the lock of other first and then apply under ours, so 2 devices copying from each other at
+	// the same time do not deadlock.
+	other.opMutex.Lock()
+	src := Device{
+		gain:              other.gain,
+		calibrationFactor: other.calibrationFactor,
+		channelBFactor:    other.channelBFactor,
+		ratio:             other.ratio,
+		factorStale:       other.factorStale,
+		capacity:          other.capacity,
+		calibrationPoints: append([]Point(nil), other.calibrationPoints...),
+		hasCalibration:    other.hasCalibration,
+		calibrationTime:   other.calibrationTime,
+	}
+	if other.calibrationFit != nil {
+		fit := *other.calibrationFit
+		src.calibrationFit = &fit
+	}
+	other.opMutex.Unlock()
+
+	d.opMutex.Lock()
+	defer d.opMutex.Unlock()
+	if src.gain != d.gain {
+		d.gain = src.gain
+		d.stateChanged()
+	}
+	d.calibrationFactor = src.calibrationFactor
+	d.channelBFactor = src.channelBFactor
+	d.ratio = src.ratio
+	d.factorStale = src.factorStale
+	d.capacity = src.capacity
+	d.calibrationPoints = src.calibrationPoints
+	d.calibrationFit = src.calibrationFit
+	d.hasCalibration = src.hasCalibration
+	d.calibrationTime = src.calibrationTime
+}
@@ -0,0 +1,428 @@
+package hx711
+
+import (
+	"errors"
+	"math"
+	"testing"
+)
+
+func TestDevice_FitCalibration(t *testing.T) {
+	tests := []struct {
+		name    string
+		raw     []uint32
+		weights []float64
+		slope   float64
+		clean   bool
+	}{
+		{
+			name:    "clean",
+			raw:     []uint32{1100, 2100, 3100, 4100},
+			weights: []float64{1, 2, 3, 4},
+			slope:   0.001,
+			clean:   true,
+		},
+		{
+			name:    "scattered",
+			raw:     []uint32{1100, 2100, 3100, 4100},
+			weights: []float64{1, 2.3, 2.8, 4.2},
+			slope:   0.001,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dtp := &counterDataPin{}
+			dtp.loadBits(tt.raw, false)
+			td := Device{
+				sck:               dtp,
+				dt:                dtp,
+				initialized:       true,
+				gain:              Gain128,
+				smoothingFactor:   1,
+				calibrationFactor: 1,
+				offset:            100,
+			}
+			if _, err := td.CalibrationQuality(); err == nil {
+				t.Log("expected an error getting the quality before fitting")
+				t.FailNow()
+			}
+			if _, err := td.FitCalibration(); err == nil {
+				t.Log("expected an error fitting without points")
+				t.FailNow()
+			}
+			for _, w := range tt.weights {
+				td.AddCalibrationPoint(w)
+			}
+			fit, err := td.FitCalibration()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if math.Abs(fit.Slope-tt.slope) > tt.slope/10 {
+				t.Logf("expected slope around %f but got %f", tt.slope, fit.Slope)
+				t.FailNow()
+			}
+			if td.GetCalibrationFactor() != fit.Slope {
+				t.Logf("expected calibration factor to be %f but is %f", fit.Slope, td.GetCalibrationFactor())
+				t.FailNow()
+			}
+			quality, err := td.CalibrationQuality()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if quality != fit || quality.Points != len(tt.weights) {
+				t.Logf("expected quality %+v but got %+v", fit, quality)
+				t.FailNow()
+			}
+			if tt.clean != (quality.ResidualRMS < 1e-9 && quality.SlopeStdErr < 1e-9 && quality.InterceptStdErr < 1e-9) {
+				t.Logf("expected clean to be %v but got %+v", tt.clean, quality)
+				t.FailNow()
+			}
+		})
+	}
+}
+
+func Test_fitLine(t *testing.T) {
+	points := []Point{{Raw: 0, Weight: 1.1}, {Raw: 2, Weight: 1.9}, {Raw: 4, Weight: 3.1}, {Raw: 6, Weight: 3.9}}
+	fit, err := fitLine(points)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// computed by hand: mean raw 3, mean weight 2.5, sxx = 20, sxy = 9.6
+	if math.Abs(fit.Slope-0.48) > 1e-9 || math.Abs(fit.Intercept-1.06) > 1e-9 {
+		t.Logf("expected slope 0.48 and intercept 1.06 but got %f and %f", fit.Slope, fit.Intercept)
+		t.FailNow()
+	}
+	// residuals are 0.04, -0.12, 0.12, -0.04
+	squares := 0.04*0.04 + 0.12*0.12 + 0.12*0.12 + 0.04*0.04
+	if math.Abs(fit.ResidualRMS-math.Sqrt(squares/4)) > 1e-9 {
+		t.Logf("expected residual RMS %f but got %f", math.Sqrt(squares/4), fit.ResidualRMS)
+		t.FailNow()
+	}
+	if math.Abs(fit.SlopeStdErr-math.Sqrt(squares/2/20)) > 1e-9 {
+		t.Logf("expected slope standard error %f but got %f", math.Sqrt(squares/2/20), fit.SlopeStdErr)
+		t.FailNow()
+	}
+	if math.Abs(fit.InterceptStdErr-math.Sqrt(squares/2*(1.0/4+9.0/20))) > 1e-9 {
+		t.Logf("expected intercept standard error %f but got %f", math.Sqrt(squares/2*(1.0/4+9.0/20)), fit.InterceptStdErr)
+		t.FailNow()
+	}
+	if _, err := fitLine([]Point{{Raw: 1, Weight: 1}, {Raw: 1, Weight: 2}}); err == nil {
+		t.Log("expected an error fitting points with the same reading")
+		t.FailNow()
+	}
+}
+
+func TestDevice_ConfigureFromRating(t *testing.T) {
+	tests := []struct {
+		gain gainLVL
+		// counts for 1000g on a 2mV/V cell: 0.002 * 2 * gain * 2^23
+		counts float64
+	}{
+		{gain: Gain128, counts: 4294967.296},
+		{gain: Gain64, counts: 2147483.648},
+		{gain: Gain32, counts: 1073741.824},
+	}
+	for _, tt := range tests {
+		td := NewPreset(CalibrationState{})
+		cf, err := td.ConfigureFromRating(1000, 2, 5, tt.gain)
+		if err != nil {
+			t.Fatal(err)
+		}
+		want := 1000 / tt.counts
+		if math.Abs(cf-want) > 1e-12 || td.GetCalibrationFactor() != cf {
+			t.Logf("gain %d expected factor %.12f but got %.12f", tt.gain, want, cf)
+			t.FailNow()
+		}
+		if td.gain != tt.gain || td.capacity != 1000 || !td.IsCalibrated() {
+			t.Logf("expected gain %d, capacity 1000 and calibrated but got %d, %f and %v", tt.gain, td.gain, td.capacity, td.IsCalibrated())
+			t.FailNow()
+		}
+		// the excitation cancels out
+		if cf2, _ := td.ConfigureFromRating(1000, 2, 3.3, tt.gain); math.Abs(cf2-cf) > 1e-12 {
+			t.Logf("expected excitation to not matter but got %.12f and %.12f", cf, cf2)
+			t.FailNow()
+		}
+	}
+	if _, err := NewPreset(CalibrationState{}).ConfigureFromRating(0, 2, 5, Gain128); err == nil {
+		t.Log("expected an error for a 0 capacity")
+		t.FailNow()
+	}
+}
+
+func TestDevice_CalibrateFullScale(t *testing.T) {
+	dtp := &counterDataPin{}
+	// 2mV/V at gain 128 is about half the chip's range
+	dtp.loadBits([]uint32{4194404, 4194404}, false)
+	td := Device{
+		sck:               dtp,
+		dt:                dtp,
+		initialized:       true,
+		gain:              Gain128,
+		smoothingFactor:   2,
+		calibrationFactor: 1,
+		offset:            100,
+		tare:              4,
+	}
+	cf, err := td.CalibrateFullScale(5000)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := 5000.0 / 4194300; cf != want || td.GetCalibrationFactor() != want {
+		t.Logf("expected factor %.12f but got %.12f", want, cf)
+		t.FailNow()
+	}
+	if td.capacity != 5000 || !td.IsCalibrated() {
+		t.Logf("expected capacity 5000 and calibrated but got %f and %v", td.capacity, td.IsCalibrated())
+		t.FailNow()
+	}
+	if _, err := td.CalibrateFullScale(0); !errors.Is(err, ErrZeroWeight) {
+		t.Logf("expected ErrZeroWeight for a 0 capacity but got %v", err)
+		t.FailNow()
+	}
+}
+
+func TestDevice_ResetCalibration(t *testing.T) {
+	dtp := &counterDataPin{}
+	dtp.loadBits([]uint32{1100, 2100}, false)
+	td := Device{
+		sck:               dtp,
+		dt:                dtp,
+		initialized:       true,
+		gain:              Gain128,
+		smoothingFactor:   1,
+		calibrationFactor: 1,
+		offset:            100,
+		tare:              10,
+	}
+	td.AddCalibrationPoint(1)
+	td.AddCalibrationPoint(2)
+	if _, err := td.FitCalibration(); err != nil {
+		t.Fatal(err)
+	}
+	td.ResetCalibration()
+	if td.IsCalibrated() || td.GetCalibrationFactor() != 1 {
+		t.Logf("expected an uncalibrated device with factor 1 but got %v and %f", td.IsCalibrated(), td.GetCalibrationFactor())
+		t.FailNow()
+	}
+	if _, err := td.CalibrationQuality(); err == nil {
+		t.Log("expected no calibration fit after reset")
+		t.FailNow()
+	}
+	if len(td.calibrationPoints) != 0 || td.ratio != (ratio{}) || !td.calibrationTime.IsZero() {
+		t.Logf("expected no calibration points, ratio or time but got %v, %v and %s", td.calibrationPoints, td.ratio, td.calibrationTime)
+		t.FailNow()
+	}
+	if td.offset != 100 || td.tare != 10 {
+		t.Logf("expected offset and tare to be kept but got %d and %d", td.offset, td.tare)
+		t.FailNow()
+	}
+}
+
+func TestDevice_CalibrationPoints(t *testing.T) {
+	dtp := &counterDataPin{}
+	dtp.loadBits([]uint32{10100, 20100, 30100}, false)
+	td := Device{
+		sck:               dtp,
+		dt:                dtp,
+		initialized:       true,
+		gain:              Gain128,
+		smoothingFactor:   1,
+		calibrationFactor: 1,
+		offset:            100,
+	}
+	td.AddCalibrationPoint(100)
+	td.AddCalibrationPoint(200)
+	saved := td.CalibrationPoints()
+	saved[0].Weight = 0
+	if td.calibrationPoints[0].Weight != 100 {
+		t.Log("expected the returned points to be a copy")
+		t.FailNow()
+	}
+	saved[0].Weight = 100
+
+	// after a restart
+	restored := Device{
+		sck:               dtp,
+		dt:                dtp,
+		initialized:       true,
+		gain:              Gain128,
+		smoothingFactor:   1,
+		calibrationFactor: 1,
+		offset:            100,
+	}
+	restored.LoadCalibrationPoints(saved)
+	if got := restored.CalibrationPoints(); len(got) != 2 || got[0] != saved[0] || got[1] != saved[1] {
+		t.Logf("expected points %v but got %v", saved, got)
+		t.FailNow()
+	}
+	restored.AddCalibrationPoint(300)
+	fit, err := restored.FitCalibration()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if fit.Points != 3 || math.Abs(fit.Slope-0.01) > 1e-12 {
+		t.Logf("expected a fit of 3 points with slope %f but got %+v", 0.01, fit)
+		t.FailNow()
+	}
+}
+
+func TestDevice_ScaleCalibrationForGain(t *testing.T) {
+	tests := []struct {
+		from, to gainLVL
+		want     float64
+	}{
+		{from: Gain128, to: Gain64, want: 0.02},
+		{from: Gain64, to: Gain128, want: 0.005},
+		{from: Gain128, to: Gain128, want: 0.01},
+		{from: Gain128, to: Gain32, want: 0.04},
+	}
+	for _, tt := range tests {
+		td := NewPreset(CalibrationState{CalibrationFactor: 0.01})
+		if f := td.ScaleCalibrationForGain(tt.from, tt.to); math.Abs(f-tt.want) > 1e-15 || td.GetCalibrationFactor() != f {
+			t.Logf("from %d to %d expected factor %f but got %f", tt.from, tt.to, tt.want, f)
+			t.FailNow()
+		}
+	}
+
+	// the same load reads the same weight at the new gain
+	dtp := &counterDataPin{}
+	dtp.loadBits([]uint32{20100, 9999, 10050}, false)
+	td := Device{
+		sck:               dtp,
+		dt:                dtp,
+		initialized:       true,
+		gain:              Gain128,
+		smoothingFactor:   1,
+		calibrationFactor: 0.01,
+		offset:            100,
+	}
+	before := td.ReadGrams()
+	td.SetGainAndChannel(Gain64)
+	td.offset = 50
+	td.ScaleCalibrationForGain(Gain128, Gain64)
+	if after := td.ReadGrams(); math.Abs(after-before) > 1e-9 {
+		t.Logf("expected %f at the new gain but got %f", before, after)
+		t.FailNow()
+	}
+}
+
+func TestDevice_CopyCalibrationFrom(t *testing.T) {
+	dtp := &counterDataPin{}
+	dtp.loadBits([]uint32{1100, 2100}, false)
+	src := Device{
+		sck:               dtp,
+		dt:                dtp,
+		initialized:       true,
+		gain:              Gain64,
+		smoothingFactor:   1,
+		calibrationFactor: 1,
+		offset:            100,
+		tare:              10,
+		capacity:          5000,
+	}
+	src.AddCalibrationPoint(1)
+	src.AddCalibrationPoint(2)
+	if _, err := src.FitCalibration(); err != nil {
+		t.Fatal(err)
+	}
+	src.SetCalibrationFactorForChannel(ChannelB, 0.5)
+
+	td := NewPreset(CalibrationState{Offset: 300, Tare: 30})
+	td.CopyCalibrationFrom(&src)
+	if !td.IsCalibrated() || td.GetCalibrationFactor() != src.calibrationFactor || td.ratio != src.ratio || td.channelBFactor != 0.5 {
+		t.Logf("expected the calibration to be copied, got factor %f, ratio %v and channel B factor %f", td.calibrationFactor, td.ratio, td.channelBFactor)
+		t.FailNow()
+	}
+	if td.gain != Gain64 || td.pendingDiscard == 0 || td.capacity != 5000 {
+		t.Logf("expected gain, capacity and a pending discard to be copied, got %d, %f and %d", td.gain, td.capacity, td.pendingDiscard)
+		t.FailNow()
+	}
+	fit, err := td.CalibrationQuality()
+	if err != nil || fit.Points != 2 || len(td.CalibrationPoints()) != 2 {
+		t.Logf("expected the multi point calibration to be copied, got %+v, %v", fit, err)
+		t.FailNow()
+	}
+	if td.offset != 300 || td.tare != 30 {
+		t.Logf("expected offset and tare to be kept but got %d and %d", td.offset, td.tare)
+		t.FailNow()
+	}
+	// the copy is not shared
+	td.AddCalibrationPoint(3)
+	if len(src.calibrationPoints) != 2 || td.calibrationFit == src.calibrationFit {
+		t.Log("expected the multi point calibration to be copied, not shared")
+		t.FailNow()
+	}
+	td.CopyCalibrationFrom(td)
+}
+
+func TestDevice_OlkalCalFactor(t *testing.T) {
+	// 696.0 is the calibration value the HX711_ADC examples ship with, that is 348000 counts for 500g
+	dtp := &counterDataPin{}
+	dtp.loadBits([]uint32{348100}, false)
+	td := Device{
+		sck:               dtp,
+		dt:                dtp,
+		initialized:       true,
+		gain:              Gain128,
+		smoothingFactor:   1,
+		calibrationFactor: 1,
+		offset:            100,
+	}
+	if cf := td.OlkalCalFactor(); cf != 1 {
+		t.Logf("expected %f when not calibrated but got %f", 1.0, cf)
+		t.FailNow()
+	}
+	if _, err := td.Calibrate(500); err != nil {
+		t.Fatal(err)
+	}
+	if cf := td.OlkalCalFactor(); math.Abs(cf-696) > 1e-9 {
+		t.Logf("expected %f but got %f", 696.0, cf)
+		t.FailNow()
+	}
+	// the Arduino library computes weight as raw / calFactor
+	if w := 348000 / td.OlkalCalFactor(); math.Abs(w-500) > 1e-9 {
+		t.Logf("expected the olkal math to give back %f but got %f", 500.0, w)
+		t.FailNow()
+	}
+}
+
+func TestDevice_LinearityError(t *testing.T) {
+	td := NewPreset(CalibrationState{})
+	if _, err := td.LinearityError(); err == nil {
+		t.Log("expected an error without calibration points")
+		t.FailNow()
+	}
+	// the middle of the range reads a little heavy, the line is 0.1 * raw + 0.4 so the worst point is
+	// 201 - 200.4 = 0.6 off
+	td.LoadCalibrationPoints([]Point{
+		{Raw: 0, Weight: 0},
+		{Raw: 1000, Weight: 100.5},
+		{Raw: 2000, Weight: 201},
+		{Raw: 3000, Weight: 300.5},
+		{Raw: 4000, Weight: 400},
+	})
+	got, err := td.LinearityError()
+	if err != nil {
+		t.Fatal(err)
+	}
+	// of the heaviest point
+	if want := 0.6 / 400 * 100; math.Abs(got-want) > 1e-9 {
+		t.Logf("expected %f%% but got %f%%", want, got)
+		t.FailNow()
+	}
+	// of the capacity of the cell
+	td.capacity = 5000
+	got, err = td.LinearityError()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := 0.6 / 5000 * 100; math.Abs(got-want) > 1e-9 {
+		t.Logf("expected %f%% but got %f%%", want, got)
+		t.FailNow()
+	}
+	// a perfect line
+	td.LoadCalibrationPoints([]Point{{Raw: 0, Weight: 0}, {Raw: 1000, Weight: 100}, {Raw: 2000, Weight: 200}})
+	if got, err := td.LinearityError(); err != nil || got > 1e-9 {
+		t.Logf("expected no linearity error but got %f%% and %v", got, err)
+		t.FailNow()
+	}
+}
@@ -0,0 +1,48 @@
+package hx711
+
+import "time"
+
+// CaptureBurst takes n single conversions, adjusted for offset and tare, and returns them for offline analysis
+// of things like impacts or peak force. There is no averaging or filtering and the device is locked once for
+// the whole capture to keep the time between samples as short as possible.
+// If interval is > 0 the samples are spaced by it instead of taken as fast as the chip allows.
+func (d *Device) CaptureBurst(n int, interval time.Duration) []int64 {
+	d.opMutex.Lock()
+	defer d.opMutex.Unlock()
+	if d.notInitialized() {
+		return nil
+	}
+	values, _ := d.captureBurst(n, interval)
+	return values
+}
+
+// CaptureBurstWithClip is CaptureBurst taking the samples as fast as the chip allows that also reports whether
+// any of them clipped at the top or bottom of the chip's range, which makes a peak force measurement worthless.
+func (d *Device) CaptureBurstWithClip(n int) (samples []int64, clipped bool) {
+	d.opMutex.Lock()
+	defer d.opMutex.Unlock()
+	if d.notInitialized() {
+		return nil, false
+	}
+	return d.captureBurst(n, 0)
+}
+
+// captureBurst takes the samples of CaptureBurst and reports whether any of them was saturated.
+func (d *Device) captureBurst(n int, interval time.Duration) ([]int64, bool) {
+	if n < 0 {
+		n = 0
+	}
+	values := make([]int64, n)
+	d.discardPending()
+	d.saturated = false
+	start := d.clock().Now()
+	for i := range values {
+		if interval > 0 && i > 0 {
+			if wait := start.Add(time.Duration(i) * interval).Sub(d.clock().Now()); wait > 0 {
+				d.clock().Sleep(wait)
+			}
+		}
+		values[i] = toInt64(d.conversion()) - d.offset - d.tare
+	}
+	return values, d.saturated
+}
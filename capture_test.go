@@ -0,0 +1,108 @@
+package hx711
+
+import (
+	"testing"
+	"time"
+)
+
+// lockCheckPin records whether the device lock was free each time DT is sampled.
+type lockCheckPin struct {
+	*counterDataPin
+	d        *Device
+	unlocked int
+}
+
+func (l *lockCheckPin) Get() bool {
+	if l.d.opMutex.TryLock() {
+		l.unlocked++
+		l.d.opMutex.Unlock()
+	}
+	return l.counterDataPin.Get()
+}
+
+func TestDevice_CaptureBurst(t *testing.T) {
+	dtp := &counterDataPin{}
+	dtp.loadBits([]uint32{1100, 1200, 1300, 1400}, false)
+	td := &Device{
+		initialized:     true,
+		sck:             dtp,
+		gain:            Gain128,
+		smoothingFactor: 10,
+		offset:          100,
+	}
+	pin := &lockCheckPin{counterDataPin: dtp, d: td}
+	td.dt = pin
+	values := td.CaptureBurst(4, 0)
+	if len(values) != 4 {
+		t.Logf("expected %d values but got %d", 4, len(values))
+		t.FailNow()
+	}
+	for i, v := range values {
+		if want := int64(1000 + 100*i); v != want {
+			t.Logf("expected value %d to be %d but got %d", i, want, v)
+			t.FailNow()
+		}
+	}
+	if pin.unlocked != 0 {
+		t.Logf("expected the device to be locked for the whole capture but it was free for %d bits", pin.unlocked)
+		t.FailNow()
+	}
+}
+
+func TestDevice_CaptureBurst_interval(t *testing.T) {
+	dtp := &counterDataPin{}
+	dtp.loadBits([]uint32{1000, 1000, 1000}, false)
+	clk := newFakeClock()
+	td := Device{
+		sck:         dtp,
+		dt:          dtp,
+		initialized: true,
+		gain:        Gain128,
+		clk:         clk,
+	}
+	start := clk.Now()
+	if values := td.CaptureBurst(3, 5*time.Millisecond); len(values) != 3 {
+		t.Logf("expected %d values but got %d", 3, len(values))
+		t.FailNow()
+	}
+	if elapsed := clk.Now().Sub(start); elapsed != 10*time.Millisecond {
+		t.Logf("expected the capture to take %s but took %s", 10*time.Millisecond, elapsed)
+		t.FailNow()
+	}
+}
+
+func TestDevice_CaptureBurstWithClip(t *testing.T) {
+	tests := []struct {
+		name    string
+		codes   []uint32
+		clipped bool
+	}{
+		{name: "clean", codes: []uint32{1100, 50000, 1100}},
+		{name: "top rail", codes: []uint32{1100, saturatedHigh, 1100}, clipped: true},
+		{name: "bottom rail", codes: []uint32{1100, 1100, saturatedLow}, clipped: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dtp := &counterDataPin{}
+			dtp.loadBits(tt.codes, false)
+			td := &Device{
+				sck:         dtp,
+				dt:          dtp,
+				initialized: true,
+				gain:        Gain128,
+				offset:      100,
+			}
+			values, clipped := td.CaptureBurstWithClip(len(tt.codes))
+			if clipped != tt.clipped {
+				t.Logf("expected clipped to be %v", tt.clipped)
+				t.FailNow()
+			}
+			for i, v := range values {
+				if want := toInt64(tt.codes[i]) - 100; v != want {
+					t.Logf("expected value %d to be %d but got %d", i, want, v)
+					t.FailNow()
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,221 @@
+package hx711
+
+import (
+	"context"
+	"fmt"
+)
+
+// Channel is one of the two inputs of the chip, channel A is read at Gain128 or Gain64 and channel B at Gain32.
+type Channel int
+
+const (
+	// ChannelA is the input read at Gain128 and Gain64.
+	ChannelA Channel = iota
+	// ChannelB is the input read at Gain32.
+	ChannelB
+)
+
+// channel returns the channel selected by the gain of the device.
+func (d *Device) channel() Channel {
+	if d.gain == Gain32 {
+		return ChannelB
+	}
+	return ChannelA
+}
+
+// factorFor returns the calibration factor of ch, channel B uses the one of channel A unless it has its own.
+func (d *Device) factorFor(ch Channel) float64 {
+	if ch == ChannelB && d.channelBFactor != 0 {
+		return d.channelBFactor
+	}
+	d.syncFactor()
+	return d.calibrationFactor
+}
+
+// factor returns the calibration factor of the selected channel.
+func (d *Device) factor() float64 {
+	return d.factorFor(d.channel())
+}
+
+// SetCalibrationFactorForChannel sets the calibration factor of a channel, reads use the one of the channel
+// selected at the time, so a different cell on channel B can be calibrated separately. Until it is set, channel
+// B uses the factor of channel A. Setting it for ChannelA is SetCalibrationFactor.
+// Fixed point reads (ReadMilligrams) only know about channel A.
+func (d *Device) SetCalibrationFactorForChannel(ch Channel, factor float64) {
+	d.opMutex.Lock()
+	defer d.opMutex.Unlock()
+	if ch == ChannelB {
+		d.channelBFactor = factor
+		return
+	}
+	d.setCalibrationFactor(factor)
+}
+
+// applyGain selects gain and channel for the following conversions, the chip only learns about it from the
+// pulses after a read, so this performs the discard reads right away, the first still belongs to the
+// previous selection. Like SetGainAndChannel an invalid g is taken as Gain128. Nothing is done if g is the
+// current selection.
+func (d *Device) applyGain(g gainLVL) {
+	if g < Gain128 || g > Gain32 {
+		g = Gain128
+	}
+	if g == d.gain {
+		return
+	}
+	d.gain = g
+	d.stateChanged()
+	d.discardPending()
+}
+
+// ReadBothChannels reads channel A, switches to channel B, reads it and switches back, the discarding of the
+// reads after each switch is taken care of.
+// Both values are calibrated with the factor of their channel, offset and tare only apply to the channel they
+// were taken on, the one the device is set to.
+// If the device was set to channel B (Gain32) channel A is read at Gain128, the device is left in the
+// selection it had before the call.
+func (d *Device) ReadBothChannels() (chA int64, chB int64) {
+	d.opMutex.Lock()
+	defer d.opMutex.Unlock()
+	if d.notInitialized() {
+		return 0, 0
+	}
+	previous := d.gain
+	gainA := previous
+	if gainA == Gain32 {
+		gainA = Gain128
+		d.applyGain(gainA)
+	}
+	a := d.sample()
+	d.applyGain(Gain32)
+	b := d.sample()
+	d.applyGain(previous)
+
+	if previous == Gain32 {
+		b -= d.offset + d.tare
+	} else {
+		a -= d.offset + d.tare
+	}
+	chA = int64(float64(a) * d.factorFor(ChannelA))
+	chB = int64(float64(b) * d.factorFor(ChannelB))
+	return chA, chB
+}
+
+// ReadAtGain reads the raw average of <SmoothingFactor> conversions at gain g and then restores the previous
+// selection, all while holding the device, the discarding of the reads after switching is taken care of.
+// The value is not adjusted for offset or tare as those were taken at the gain of the device. An invalid g is
+// taken as Gain128, like SetGainAndChannel does.
+func (d *Device) ReadAtGain(g gainLVL) int64 {
+	d.opMutex.Lock()
+	defer d.opMutex.Unlock()
+	if d.notInitialized() {
+		return 0
+	}
+	previous := d.gain
+	if g == previous {
+		return d.sample()
+	}
+	d.applyGain(g)
+	raw := d.sample()
+	d.applyGain(previous)
+	return raw
+}
+
+// ReadAutoGain reads channel A at Gain128 and, if that saturates, again at Gain64, like a multimeter picking
+// its range, so small loads get the best resolution and large ones still read. It returns the raw average of
+// <SmoothingFactor> conversions, not adjusted for offset or tare, and the gain it was read at, the second read
+// might still be saturated, see WasSaturated. Gain32 is not a lower range but channel B, which is another input,
+// so a device set to channel B just reads it. The device is left in the selection it had before the call.
+func (d *Device) ReadAutoGain() (int64, gainLVL) {
+	d.opMutex.Lock()
+	defer d.opMutex.Unlock()
+	if d.notInitialized() {
+		return 0, d.gain
+	}
+	previous := d.gain
+	if previous == Gain32 {
+		return d.sample(), Gain32
+	}
+	used := Gain128
+	d.applyGain(used)
+	raw := d.sample()
+	if d.saturated {
+		used = Gain64
+		d.applyGain(used)
+		raw = d.sample()
+	}
+	d.applyGain(previous)
+	return raw, used
+}
+
+// ReadTemperatureChannel reads channel B, where some boards route a thermistor, and returns it passed through
+// convert which should turn the raw value into degrees. The discarding of the reads after switching channels is
+// taken care of and the device is left in the selection it had before the call.
+func (d *Device) ReadTemperatureChannel(convert func(raw int64) float64) float64 {
+	d.opMutex.Lock()
+	defer d.opMutex.Unlock()
+	if d.notInitialized() {
+		return 0
+	}
+	previous := d.gain
+	if previous != Gain32 {
+		d.applyGain(Gain32)
+	}
+	raw := d.sample()
+	if previous != Gain32 {
+		d.applyGain(previous)
+	}
+	return convert(raw)
+}
+
+// VerifyRelease checks that the chip releases DT after a read, after the discard read it reads a conversion and
+// checks that DT went high with the pulses following the data, which the datasheet says happens on the 25th
+// pulse as the chip starts the next conversion. If DT stays low the chip, or a clone, is not clocking as
+// expected and an error wrapping ErrNotReleased is returned. Note DT goes high regardless of how many pulses
+// follow the 25th so this does not tell whether the gain and channel selection was taken.
+func (d *Device) VerifyRelease() error {
+	d.opMutex.Lock()
+	defer d.opMutex.Unlock()
+	if d.notInitialized() {
+		return ErrNotInitialized
+	}
+	d.discardPending()
+	if d.exclusiveClock {
+		clockMutex.Lock()
+		defer clockMutex.Unlock()
+	}
+	ctx, cancel := d.timeoutContext()
+	defer cancel()
+	if err := d.waitReady(ctx); err != nil {
+		return d.contextError(err)
+	}
+	value, _ := d.readData(context.Background(), 24)
+	d.setGainAndChannel()
+	if !d.dt.Get() {
+		return fmt.Errorf("DT still low after %d pulses: %w", 24+int(d.gain), ErrNotReleased)
+	}
+	if d.inverted {
+		value = invert(value)
+	}
+	d.lastConversion = value
+	return nil
+}
+
+// ReadDataOnly clocks out the 24 data bits of a conversion without the gain pulses that must follow them,
+// those are sent by PulseGain which must be called before the next conversion is read. This is for expert
+// timing control, ie: to choreograph several devices, no discards or averaging are done, the value is raw.
+func (d *Device) ReadDataOnly() uint32 {
+	d.opMutex.Lock()
+	defer d.opMutex.Unlock()
+	if d.notInitialized() {
+		return 0
+	}
+	value, _ := d.readData(context.Background(), 24)
+	return value
+}
+
+// PulseGain sends the pulses selecting gain and channel for the next conversion, see ReadDataOnly.
+func (d *Device) PulseGain() {
+	d.opMutex.Lock()
+	defer d.opMutex.Unlock()
+	d.setGainAndChannel()
+}
@@ -0,0 +1,298 @@
+package hx711
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestDevice_ReadBothChannels(t *testing.T) {
+	dtp := &counterDataPin{}
+	// 2 reads of A, discard after switching to B, 2 reads of B, discard after switching back to A
+	dtp.loadBits([]uint32{1000, 1000, 1000, 2000, 2000, 2000}, false)
+	td := Device{
+		sck:               dtp,
+		dt:                dtp,
+		initialized:       true,
+		gain:              Gain128,
+		smoothingFactor:   2,
+		calibrationFactor: 2,
+		offset:            100,
+	}
+	chA, chB := td.ReadBothChannels()
+	if chA != 1800 || chB != 4000 {
+		t.Logf("expected channel A to be %d and B %d but got %d and %d", 1800, 4000, chA, chB)
+		t.FailNow()
+	}
+	if td.gain != Gain128 {
+		t.Logf("expected device to be back in Gain128 but is in %d", td.gain)
+		t.FailNow()
+	}
+	if dtp.getIdx != len(dtp.get) {
+		t.Logf("expected %d bits to be read but %d were", len(dtp.get), dtp.getIdx)
+		t.FailNow()
+	}
+	want := 2*(24+int(Gain128)) + (24 + int(Gain32)) + 2*(24+int(Gain32)) + (24 + int(Gain128))
+	if dtp.countL != dtp.countH || dtp.countL != want {
+		t.Logf("expected %d ticks but tick was called %d times for High and %d times for Low", want, dtp.countH, dtp.countL)
+		t.FailNow()
+	}
+}
+
+func TestDevice_SetCalibrationFactorForChannel(t *testing.T) {
+	dtp := &counterDataPin{}
+	// A, then B twice through ReadBothChannels with their discards, then B again
+	dtp.loadBits([]uint32{1100, 1100, 2000, 2000, 1100, 9999, 2000}, false)
+	td := Device{
+		sck:               dtp,
+		dt:                dtp,
+		initialized:       true,
+		gain:              Gain128,
+		smoothingFactor:   1,
+		calibrationFactor: 1,
+		offset:            100,
+	}
+	td.SetCalibrationFactorForChannel(ChannelA, 2)
+	td.SetCalibrationFactorForChannel(ChannelB, 0.5)
+	if v := td.ReadCalibrated(); v != 2000 {
+		t.Logf("expected channel A to read %d but got %d", 2000, v)
+		t.FailNow()
+	}
+	chA, chB := td.ReadBothChannels()
+	if chA != 2000 || chB != 1000 {
+		t.Logf("expected channel A to be %d and B %d but got %d and %d", 2000, 1000, chA, chB)
+		t.FailNow()
+	}
+	td.SetGainAndChannel(Gain32)
+	// offset and tare only apply to channel A
+	td.offset = 0
+	if g := td.ReadGrams(); g != 1000 {
+		t.Logf("expected channel B to read %f but got %f", 1000.0, g)
+		t.FailNow()
+	}
+	td.ResetCalibration()
+	if td.factorFor(ChannelB) != 1 {
+		t.Logf("expected channel B to go back to the factor of A but is %f", td.factorFor(ChannelB))
+		t.FailNow()
+	}
+}
+
+func TestDevice_ReadBothChannels_fromB(t *testing.T) {
+	dtp := &counterDataPin{}
+	// discard after switching to A, read A, discard after switching to B, read B, discard after switching back
+	dtp.loadBits([]uint32{2000, 1000, 1000, 2000, 2000}, false)
+	td := Device{
+		sck:               dtp,
+		dt:                dtp,
+		initialized:       true,
+		gain:              Gain32,
+		smoothingFactor:   1,
+		calibrationFactor: 1,
+		// taken on channel B
+		offset: 100,
+		tare:   50,
+	}
+	chA, chB := td.ReadBothChannels()
+	if chA != 1000 || chB != 1850 {
+		t.Logf("expected channel A to be %d and B %d but got %d and %d", 1000, 1850, chA, chB)
+		t.FailNow()
+	}
+	if td.gain != Gain32 {
+		t.Logf("expected device to be back in Gain32 but is in %d", td.gain)
+		t.FailNow()
+	}
+}
+
+func TestDevice_ReadAtGain(t *testing.T) {
+	dtp := &counterDataPin{}
+	// discard after switching to 64, 2 reads at 64, discard after switching back to 128
+	dtp.loadBits([]uint32{1000, 500, 500, 500}, false)
+	td := Device{
+		sck:             dtp,
+		dt:              dtp,
+		initialized:     true,
+		gain:            Gain128,
+		smoothingFactor: 2,
+		offset:          100,
+	}
+	if v := td.ReadAtGain(Gain64); v != 500 {
+		t.Logf("expected %d but got %d", 500, v)
+		t.FailNow()
+	}
+	if td.gain != Gain128 {
+		t.Logf("expected device to be back in Gain128 but is in %d", td.gain)
+		t.FailNow()
+	}
+	want := (24 + int(Gain64)) + 2*(24+int(Gain64)) + (24 + int(Gain128))
+	if dtp.countL != dtp.countH || dtp.countL != want {
+		t.Logf("expected %d ticks but tick was called %d times for High and %d times for Low", want, dtp.countH, dtp.countL)
+		t.FailNow()
+	}
+}
+
+func TestDevice_ReadAutoGain(t *testing.T) {
+	tests := []struct {
+		name     string
+		previous gainLVL
+		codes    []uint32
+		want     int64
+		gain     gainLVL
+	}{
+		{name: "fits at 128", previous: Gain128, codes: []uint32{3000}, want: 3000, gain: Gain128},
+		// saturated at 128, discard after switching to 64, read at 64, discard after switching back to 128
+		{name: "saturated at 128", previous: Gain128, codes: []uint32{saturatedHigh, 9999, 5000, 9999}, want: 5000, gain: Gain64},
+		{name: "saturated low at 128", previous: Gain128, codes: []uint32{saturatedLow, 9999, 0xFFEC78, 9999}, want: -5000, gain: Gain64},
+		// discard after switching to 128, saturated, discard after switching to 64 and read there
+		{name: "from 64", previous: Gain64, codes: []uint32{9999, saturatedHigh, 9999, 5000}, want: 5000, gain: Gain64},
+		{name: "channel B", previous: Gain32, codes: []uint32{saturatedHigh}, want: 0x7FFFFF, gain: Gain32},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dtp := &counterDataPin{}
+			dtp.loadBits(tt.codes, false)
+			td := Device{
+				sck:             dtp,
+				dt:              dtp,
+				initialized:     true,
+				gain:            tt.previous,
+				smoothingFactor: 1,
+				offset:          100,
+			}
+			v, g := td.ReadAutoGain()
+			if v != tt.want || g != tt.gain {
+				t.Logf("expected %d at gain %d but got %d at gain %d", tt.want, tt.gain, v, g)
+				t.FailNow()
+			}
+			if td.gain != tt.previous || dtp.getIdx != len(tt.codes)*24 {
+				t.Logf("expected the device back at gain %d after %d conversions but is at %d after %d bits", tt.previous, len(tt.codes), td.gain, dtp.getIdx)
+				t.FailNow()
+			}
+		})
+	}
+}
+
+func TestDevice_ReadTemperatureChannel(t *testing.T) {
+	dtp := &counterDataPin{}
+	// discard after switching to B, 2 reads of B, discard after switching back to A
+	dtp.loadBits([]uint32{1000, 30000, 30000, 30000}, false)
+	td := Device{
+		sck:             dtp,
+		dt:              dtp,
+		initialized:     true,
+		gain:            Gain64,
+		smoothingFactor: 2,
+		offset:          100,
+	}
+	// 0°C at 20000 and 1000 counts per degree
+	c := td.ReadTemperatureChannel(func(raw int64) float64 {
+		return float64(raw-20000) / 1000
+	})
+	if c != 10 {
+		t.Logf("expected %f°C but got %f", 10.0, c)
+		t.FailNow()
+	}
+	if td.gain != Gain64 {
+		t.Logf("expected device to be back in Gain64 but is in %d", td.gain)
+		t.FailNow()
+	}
+	want := (24 + int(Gain32)) + 2*(24+int(Gain32)) + (24 + int(Gain64))
+	if dtp.countL != dtp.countH || dtp.countL != want {
+		t.Logf("expected %d ticks but tick was called %d times for High and %d times for Low", want, dtp.countH, dtp.countL)
+		t.FailNow()
+	}
+}
+
+func TestDevice_VerifyRelease(t *testing.T) {
+	tests := []struct {
+		name     string
+		released bool
+		inverted bool
+		want     error
+	}{
+		{name: "released after the pulses", released: true},
+		{name: "inverted", released: true, inverted: true},
+		{name: "not released", released: false, want: ErrNotReleased},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dtp := &counterDataPin{}
+			// the discard, then ready, the conversion and DT after the selection pulses
+			dtp.loadBits([]uint32{9999}, false)
+			dtp.get = append(dtp.get, false)
+			dtp.loadBits([]uint32{2000}, false)
+			dtp.get = append(dtp.get, tt.released)
+			td := Device{
+				sck:         dtp,
+				dt:          dtp,
+				initialized: true,
+				gain:        Gain128,
+				inverted:    tt.inverted,
+			}
+			td.SetGainAndChannel(Gain32)
+			if err := td.VerifyRelease(); !errors.Is(err, tt.want) {
+				t.Logf("expected %v but got %v", tt.want, err)
+				t.FailNow()
+			}
+			want := uint32(2000)
+			if tt.inverted {
+				want = invert(want)
+			}
+			if tt.want == nil && td.lastConversion != want {
+				t.Logf("expected the last conversion to be %d but is %d", want, td.lastConversion)
+				t.FailNow()
+			}
+			if dtp.getIdx != len(dtp.get) {
+				t.Logf("expected %d bits to be read but %d were", len(dtp.get), dtp.getIdx)
+				t.FailNow()
+			}
+		})
+	}
+}
+
+func TestDevice_ReadAtGain_invalid(t *testing.T) {
+	for _, g := range []gainLVL{0, 4} {
+		dtp := &counterDataPin{}
+		dtp.loadBits([]uint32{500}, false)
+		td := Device{
+			sck:             dtp,
+			dt:              dtp,
+			initialized:     true,
+			gain:            Gain128,
+			smoothingFactor: 1,
+		}
+		if v := td.ReadAtGain(g); v != 500 {
+			t.Logf("expected %d at gain %d but got %d", 500, g, v)
+			t.FailNow()
+		}
+		if td.gain != Gain128 || dtp.countH != 24+int(Gain128) {
+			t.Logf("expected gain %d to be read at Gain128 but tick was called %d times and the device is in %d", g, dtp.countH, td.gain)
+			t.FailNow()
+		}
+	}
+}
+
+func TestDevice_ReadDataOnly(t *testing.T) {
+	for _, g := range []gainLVL{Gain128, Gain64, Gain32} {
+		dtp := &counterDataPin{}
+		dtp.loadBits([]uint32{50000}, false)
+		td := Device{
+			sck:         dtp,
+			dt:          dtp,
+			initialized: true,
+			gain:        g,
+		}
+		if v := td.ReadDataOnly(); v != 50000 {
+			t.Logf("expected %d but got %d", 50000, v)
+			t.FailNow()
+		}
+		if dtp.countL != dtp.countH || dtp.countL != 24 {
+			t.Logf("expected 24 ticks for the data but tick was called %d times for High and %d times for Low", dtp.countH, dtp.countL)
+			t.FailNow()
+		}
+		dtp.reset()
+		td.PulseGain()
+		if dtp.countL != dtp.countH || dtp.countL != int(g) {
+			t.Logf("Gain is %d but tick was called %d times for High and %d times for Low", g, dtp.countH, dtp.countL)
+			t.FailNow()
+		}
+	}
+}
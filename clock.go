@@ -0,0 +1,25 @@
+package hx711
+
+import "time"
+
+// clock is the source of time for everything in a Device, so it can be replaced in tests.
+type clock interface {
+	Now() time.Time
+	Sleep(d time.Duration)
+	After(d time.Duration) <-chan time.Time
+}
+
+// realClock is a clock backed by the time package.
+type realClock struct{}
+
+func (realClock) Now() time.Time                         { return time.Now() }
+func (realClock) Sleep(d time.Duration)                  { time.Sleep(d) }
+func (realClock) After(d time.Duration) <-chan time.Time { return time.After(d) }
+
+// clock returns the clock of the device.
+func (d *Device) clock() clock {
+	if d.clk == nil {
+		return realClock{}
+	}
+	return d.clk
+}
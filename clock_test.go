@@ -0,0 +1,124 @@
+package hx711
+
+import (
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeClock is a clock that only moves when told to, sleeping advances it.
+type fakeClock struct {
+	mu      sync.Mutex
+	now     time.Time
+	waiters []fakeWaiter
+}
+
+type fakeWaiter struct {
+	at time.Time
+	c  chan time.Time
+}
+
+func newFakeClock() *fakeClock {
+	return &fakeClock{now: time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC)}
+}
+
+func (f *fakeClock) Now() time.Time {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.now
+}
+
+func (f *fakeClock) Sleep(d time.Duration) {
+	f.Advance(d)
+}
+
+func (f *fakeClock) After(d time.Duration) <-chan time.Time {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	c := make(chan time.Time, 1)
+	if d <= 0 {
+		c <- f.now
+		return c
+	}
+	f.waiters = append(f.waiters, fakeWaiter{at: f.now.Add(d), c: c})
+	return c
+}
+
+// Advance moves the clock forward firing the After channels that are due.
+func (f *fakeClock) Advance(d time.Duration) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.now = f.now.Add(d)
+	pending := f.waiters[:0]
+	for _, w := range f.waiters {
+		if w.at.After(f.now) {
+			pending = append(pending, w)
+			continue
+		}
+		w.c <- f.now
+	}
+	f.waiters = pending
+}
+
+// Waiters returns the amount of After channels that did not fire yet.
+func (f *fakeClock) Waiters() int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return len(f.waiters)
+}
+
+func TestFakeClock(t *testing.T) {
+	clk := newFakeClock()
+	start := clk.Now()
+	after := clk.After(time.Second)
+	clk.Sleep(999 * time.Millisecond)
+	select {
+	case <-after:
+		t.Log("expected After to not fire before its time")
+		t.FailNow()
+	default:
+	}
+	clk.Advance(time.Millisecond)
+	select {
+	case at := <-after:
+		if at.Sub(start) != time.Second {
+			t.Logf("expected After to fire a second after start but fired %s after", at.Sub(start))
+			t.FailNow()
+		}
+	default:
+		t.Log("expected After to fire")
+		t.FailNow()
+	}
+}
+
+func TestDevice_clock(t *testing.T) {
+	td := Device{}
+	if _, ok := td.clock().(realClock); !ok {
+		t.Logf("expected the default clock to be the real one but is %T", td.clock())
+		t.FailNow()
+	}
+	clk := newFakeClock()
+	dtp := &counterDataPin{loop: true}
+	dtp.loadBits([]uint32{1000}, false)
+	td = Device{
+		sck:             dtp,
+		dt:              dtp,
+		initialized:     true,
+		gain:            Gain128,
+		smoothingFactor: 1,
+		tickDelay:       time.Microsecond,
+		clk:             clk,
+	}
+	start := clk.Now()
+	td.Read()
+	// every tick sleeps twice
+	if elapsed := clk.Now().Sub(start); elapsed != 2*time.Duration(24+int(Gain128))*time.Microsecond {
+		t.Logf("expected the read to sleep through the clock for %s but it did for %s",
+			2*time.Duration(24+int(Gain128))*time.Microsecond, elapsed)
+		t.FailNow()
+	}
+	if td.LastReadTime() != clk.Now() {
+		t.Logf("expected the last read time to be %s but is %s", clk.Now(), td.LastReadTime())
+		t.FailNow()
+	}
+}
@@ -0,0 +1,42 @@
+package hx711
+
+// These mirror the API most other hx711 libraries copied from the Arduino one (GetValue, GetUnits, SetScale,
+// SetOffset...), to make moving code over easier. New code should use the methods they call.
+
+// GetValue returns the read adjusted for offset and tare, it is Read.
+func (d *Device) GetValue() int64 {
+	return d.Read()
+}
+
+// GetUnits returns the read in the calibration unit, it is ReadGrams.
+func (d *Device) GetUnits() float64 {
+	return d.ReadGrams()
+}
+
+// SetScale sets the scale as other libraries take it, raw units per calibration unit, which is the inverse of
+// our calibration factor, see SetCalibrationFactor. A scale of 0 is ignored.
+func (d *Device) SetScale(scale float64) {
+	if scale == 0 {
+		return
+	}
+	d.SetCalibrationFactor(1 / scale)
+}
+
+// GetScale returns the scale as other libraries take it, raw units per calibration unit, see SetScale.
+func (d *Device) GetScale() float64 {
+	return 1 / d.GetCalibrationFactor()
+}
+
+// SetOffset sets the zero offset, in raw units, the value Zero would have measured on the empty scale.
+func (d *Device) SetOffset(offset int64) {
+	d.opMutex.Lock()
+	defer d.opMutex.Unlock()
+	d.offset = offset
+}
+
+// GetOffset returns the zero offset in raw units, see SetOffset.
+func (d *Device) GetOffset() int64 {
+	d.opMutex.Lock()
+	defer d.opMutex.Unlock()
+	return d.offset
+}
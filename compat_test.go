@@ -0,0 +1,43 @@
+package hx711
+
+import "testing"
+
+func TestDevice_compat(t *testing.T) {
+	dtp := &counterDataPin{}
+	dtp.loadBits([]uint32{1100, 1100, 1100, 1100}, false)
+	td := Device{
+		sck:               dtp,
+		dt:                dtp,
+		initialized:       true,
+		gain:              Gain128,
+		smoothingFactor:   2,
+		calibrationFactor: 1,
+	}
+	td.SetOffset(100)
+	if o := td.GetOffset(); o != 100 || td.offset != 100 {
+		t.Logf("expected offset %d but got %d", 100, o)
+		t.FailNow()
+	}
+	td.SetScale(4)
+	if f := td.GetCalibrationFactor(); f != 0.25 || !td.IsCalibrated() {
+		t.Logf("expected a scale of 4 to be a factor of 0.25 but got %f", f)
+		t.FailNow()
+	}
+	if s := td.GetScale(); s != 4 {
+		t.Logf("expected scale %f but got %f", 4.0, s)
+		t.FailNow()
+	}
+	td.SetScale(0)
+	if s := td.GetScale(); s != 4 {
+		t.Logf("expected a scale of 0 to be ignored but got %f", s)
+		t.FailNow()
+	}
+	if v := td.GetValue(); v != 1000 {
+		t.Logf("expected GetValue to be %d but got %d", 1000, v)
+		t.FailNow()
+	}
+	if v := td.GetUnits(); v != 250 {
+		t.Logf("expected GetUnits to be %f but got %f", 250.0, v)
+		t.FailNow()
+	}
+}
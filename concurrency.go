@@ -0,0 +1,22 @@
+package hx711
+
+import "sync"
+
+// clockMutex is held during a read by all devices with an exclusive clock.
+var clockMutex sync.Mutex
+
+// SetExclusiveClock makes each read of this device hold a lock shared by all the devices with an exclusive
+// clock, so only one of them bit bangs at a time.
+//
+// Devices do not share any state so reading several of them from different goroutines is safe, each read is
+// locked per device. What is not guaranteed is the timing: a goroutine switch in the middle of a read stretches
+// the clock pulse it happens in, if SCK stays high for more than 60µs the chip powers down and the read is lost.
+// With a cooperative scheduler, like TinyGo's, switches happen on the sleeps between pulses so another device
+// bit banging at the same time adds its own work to our pulses. Enabling this on all the devices trades
+// concurrency for pulses that are only stretched by the scheduler itself, reads of a device are as long
+// as before but they will wait for reads of other devices to finish.
+func (d *Device) SetExclusiveClock(exclusive bool) {
+	d.opMutex.Lock()
+	defer d.opMutex.Unlock()
+	d.exclusiveClock = exclusive
+}
@@ -0,0 +1,67 @@
+package hx711
+
+import (
+	"sync"
+	"sync/atomic"
+	"testing"
+)
+
+// busPin is a clock pin that detects another busPin on the same bus being high at the same time.
+type busPin struct {
+	*counterDataPin
+	high    *int32
+	overlap *int32
+}
+
+func (b *busPin) High() {
+	if atomic.AddInt32(b.high, 1) > 1 {
+		atomic.StoreInt32(b.overlap, 1)
+	}
+	b.counterDataPin.High()
+}
+
+func (b *busPin) Low() {
+	atomic.AddInt32(b.high, -1)
+	b.counterDataPin.Low()
+}
+
+func TestDevice_SetExclusiveClock(t *testing.T) {
+	var high, overlap int32
+	devices := make([]*Device, 2)
+	for i := range devices {
+		dtp := &counterDataPin{loop: true}
+		dtp.loadBits([]uint32{uint32(1000 * (i + 1))}, false)
+		devices[i] = &Device{
+			sck:             &busPin{counterDataPin: dtp, high: &high, overlap: &overlap},
+			dt:              dtp,
+			initialized:     true,
+			gain:            Gain128,
+			smoothingFactor: 5,
+			tickDelay:       1,
+		}
+		devices[i].SetExclusiveClock(true)
+	}
+	var wg sync.WaitGroup
+	errs := make(chan int64, 2*100)
+	for i, d := range devices {
+		wg.Add(1)
+		go func(want int64, d *Device) {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				if v := d.Read(); v != want {
+					errs <- v
+				}
+			}
+		}(int64(1000*(i+1)), d)
+	}
+	wg.Wait()
+	close(errs)
+	for v := range errs {
+		t.Logf("got an unexpected value %d reading concurrently", v)
+		t.FailNow()
+	}
+	if atomic.LoadInt32(&overlap) != 0 {
+		t.Log("clock pulses of two devices with exclusive clocks overlapped")
+		t.FailNow()
+	}
+}
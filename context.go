@@ -0,0 +1,200 @@
+package hx711
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// waitReady waits until the chip has a conversion ready or ctx is done.
+func (d *Device) waitReady(ctx context.Context) error {
+	for !d.isReady() {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// clockContext is a context with a deadline on the clock of the device instead of the wall clock, so timeouts
+// follow an injected clock like everything else.
+type clockContext struct {
+	context.Context
+	deadline time.Time
+	done     chan struct{}
+	mu       sync.Mutex
+	err      error
+}
+
+func (c *clockContext) Deadline() (time.Time, bool) { return c.deadline, true }
+func (c *clockContext) Done() <-chan struct{}       { return c.done }
+
+func (c *clockContext) Err() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.err
+}
+
+func (c *clockContext) cancel(err error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.err == nil {
+		c.err = err
+		close(c.done)
+	}
+}
+
+// withTimeout is context.WithTimeout on the clock of the device.
+func (d *Device) withTimeout(parent context.Context, timeout time.Duration) (context.Context, context.CancelFunc) {
+	c := &clockContext{Context: parent, deadline: d.clock().Now().Add(timeout), done: make(chan struct{})}
+	if timeout <= 0 {
+		c.cancel(context.DeadlineExceeded)
+		return c, func() {}
+	}
+	expired := d.clock().After(timeout)
+	go func() {
+		select {
+		case <-expired:
+			c.cancel(context.DeadlineExceeded)
+		case <-parent.Done():
+			c.cancel(parent.Err())
+		case <-c.done:
+		}
+	}()
+	return c, func() { c.cancel(context.Canceled) }
+}
+
+// timeoutContext returns a context done after the read timeout, see SetReadTimeout, or one that is never done
+// if there is none.
+func (d *Device) timeoutContext() (context.Context, context.CancelFunc) {
+	if d.readTimeout > 0 {
+		return d.withTimeout(context.Background(), d.readTimeout)
+	}
+	return context.Background(), func() {}
+}
+
+// sampleContext is sampleN waiting for the chip before each conversion and giving up when ctx is done.
+func (d *Device) sampleContext(ctx context.Context, n int) (int64, error) {
+	var err error
+	for ; d.pendingDiscard > 0 && err == nil; d.pendingDiscard-- {
+		_, err = d.convert(ctx)
+	}
+	d.saturated = false
+	if err != nil {
+		return 0, err
+	}
+	value, err := d.averageConversions(ctx, n)
+	if err != nil {
+		return 0, err
+	}
+	return value, nil
+}
+
+// ReadContext is Read waiting for the chip to be ready before each conversion, it gives up and returns
+// ctx's error when ctx is done, a passed deadline also matches ErrTimeout, or ErrNoSensor if the chip was
+// never seen ready. A conversion interrupted halfway is clocked out anyway so the chip is left ready for the
+// next read.
+// If any of the conversions was saturated the value is returned along with ErrSaturated, a Device that did not
+// go through New, NewWithOptions or Initialize returns ErrNotInitialized.
+func (d *Device) ReadContext(ctx context.Context) (int64, error) {
+	d.opMutex.Lock()
+	defer d.opMutex.Unlock()
+	if !d.initialized {
+		return 0, ErrNotInitialized
+	}
+	if d.cached() {
+		return d.lastSample - d.offset - d.tare, nil
+	}
+	if _, ok := ctx.Deadline(); !ok && d.readTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = d.withTimeout(ctx, d.readTimeout)
+		defer cancel()
+	}
+	raw, err := d.sampleContext(ctx, d.smoothingFactor)
+	if err != nil {
+		return 0, d.contextError(err)
+	}
+	value := d.record(raw) - d.offset - d.tare
+	if d.saturated {
+		return value, ErrSaturated
+	}
+	return value, nil
+}
+
+// SetReadTimeout makes reads wait for the chip to be ready before each conversion and give up after timeout,
+// so a missing or stuck chip doesn't stall the application: Read and the methods built on it (ReadCalibrated,
+// ReadGrams, WeighStable...) return the last value read and record the error in Diagnostics, ReadContext
+// returns it, matching ErrTimeout or ErrNoSensor like a passed deadline does. Waiting for the chip elsewhere,
+// in Initialize, VerifyRelease or ScheduledLowPowerRead, gives up after it too. A ctx passed to ReadContext that
+// has its own deadline takes precedence. 0, the default, disables it, reads don't wait for the chip at all.
+func (d *Device) SetReadTimeout(timeout time.Duration) {
+	d.opMutex.Lock()
+	defer d.opMutex.Unlock()
+	if timeout < 0 {
+		timeout = 0
+	}
+	d.readTimeout = timeout
+}
+
+// ReadDeadline is ReadContext giving up at t, as told by the clock of the device, if t already passed it returns
+// ErrTimeout without touching the chip.
+func (d *Device) ReadDeadline(t time.Time) (int64, error) {
+	d.opMutex.Lock()
+	initialized := d.initialized
+	d.opMutex.Unlock()
+	if !initialized {
+		return 0, ErrNotInitialized
+	}
+	remaining := t.Sub(d.clock().Now())
+	if remaining <= 0 {
+		return 0, ctxError{sentinel: ErrTimeout, err: context.DeadlineExceeded}
+	}
+	ctx, cancel := d.withTimeout(context.Background(), remaining)
+	defer cancel()
+	return d.ReadContext(ctx)
+}
+
+// ReadOnTrigger waits for trigger to fire (a send or a close) and then reads like Read, to measure exactly when
+// an external event happens, ie: a test rig signaling that a known force is applied. If trigger does not fire
+// within timeout an error wrapping ErrTimeout is returned without reading.
+func (d *Device) ReadOnTrigger(trigger <-chan struct{}, timeout time.Duration) (int64, error) {
+	select {
+	case <-trigger:
+	case <-d.clock().After(timeout):
+		return 0, fmt.Errorf("no trigger in %s: %w", timeout, ErrTimeout)
+	}
+	d.opMutex.Lock()
+	defer d.opMutex.Unlock()
+	if !d.initialized {
+		return 0, ErrNotInitialized
+	}
+	return d.measure() - d.offset - d.tare, nil
+}
+
+// ReadOnChange reads like Read until the value differs by more than threshold from the one it returned last
+// time and returns it, so an event driven logger only sees actual changes. The first call returns the first
+// read. If the value does not change within timeout an error wrapping ErrTimeout is returned. The device is
+// only held during each read.
+func (d *Device) ReadOnChange(threshold int64, timeout time.Duration) (int64, error) {
+	deadline := d.clock().Now().Add(timeout)
+	for {
+		d.opMutex.Lock()
+		if !d.initialized {
+			d.opMutex.Unlock()
+			return 0, ErrNotInitialized
+		}
+		v := d.measure() - d.offset - d.tare
+		changed := !d.hasReported || abs64(v-d.lastReported) > threshold
+		if changed {
+			d.lastReported, d.hasReported = v, true
+		}
+		d.opMutex.Unlock()
+		if changed {
+			return v, nil
+		}
+		if d.clock().Now().After(deadline) {
+			return 0, fmt.Errorf("value did not change in %s: %w", timeout, ErrTimeout)
+		}
+	}
+}
@@ -0,0 +1,382 @@
+package hx711
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+// cancelPin cancels a context after DT was sampled a given amount of times.
+type cancelPin struct {
+	*counterDataPin
+	after  int
+	cancel func()
+}
+
+func (c *cancelPin) Get() bool {
+	b := c.counterDataPin.Get()
+	if c.getIdx == c.after {
+		c.cancel()
+	}
+	return b
+}
+
+func TestDevice_ReadContext(t *testing.T) {
+	dtp := &counterDataPin{}
+	dtp.loadBits([]uint32{1100, 1100}, false)
+	td := Device{
+		sck:             dtp,
+		dt:              dtp,
+		initialized:     true,
+		gain:            Gain128,
+		smoothingFactor: 2,
+		offset:          100,
+	}
+	// a context that can't be done reads right away like Read does
+	v, err := td.ReadContext(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if v != 1000 || dtp.getIdx != 2*24 {
+		t.Logf("expected %d reading %d bits but got %d reading %d bits", 1000, 2*24, v, dtp.getIdx)
+		t.FailNow()
+	}
+
+	// not ready twice, then ready before each conversion
+	dtp = &counterDataPin{}
+	dtp.loadBits([]uint32{1100, 1100}, false)
+	dtp.get = append([]bool{true, true, false}, dtp.get...)
+	dtp.get = append(dtp.get[:3+24], append([]bool{false}, dtp.get[3+24:]...)...)
+	td.sck, td.dt = dtp, dtp
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	v, err = td.ReadContext(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if v != 1000 {
+		t.Logf("expected %d but got %d", 1000, v)
+		t.FailNow()
+	}
+	if dtp.getIdx != len(dtp.get) {
+		t.Logf("expected all %d DT levels to be read but %d were", len(dtp.get), dtp.getIdx)
+		t.FailNow()
+	}
+}
+
+func TestDevice_ReadContext_notReady(t *testing.T) {
+	dtp := &counterDataPin{loop: true}
+	dtp.get = []bool{true}
+	td := Device{
+		sck:             dtp,
+		dt:              dtp,
+		initialized:     true,
+		gain:            Gain128,
+		smoothingFactor: 2,
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if _, err := td.ReadContext(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Logf("expected %v but got %v", context.DeadlineExceeded, err)
+		t.FailNow()
+	}
+	if dtp.countH != 0 {
+		t.Logf("expected no clock pulses for a chip that is never ready but got %d", dtp.countH)
+		t.FailNow()
+	}
+}
+
+func TestDevice_ReadContext_abortMidRead(t *testing.T) {
+	dtp := &counterDataPin{}
+	dtp.loadBits([]uint32{1000}, false)
+	// ready, then only the first 10 bits of the aborted conversion get sampled
+	dtp.get = append([]bool{false}, dtp.get[:10]...)
+	dtp.get = append(dtp.get, false)
+	dtp.loadBits([]uint32{2000}, false)
+	ctx, cancel := context.WithCancel(context.Background())
+	pin := &cancelPin{counterDataPin: dtp, after: 1 + 10, cancel: cancel}
+	td := Device{
+		sck:             dtp,
+		dt:              pin,
+		initialized:     true,
+		gain:            Gain64,
+		smoothingFactor: 1,
+	}
+	if _, err := td.ReadContext(ctx); !errors.Is(err, context.Canceled) {
+		t.Logf("expected %v but got %v", context.Canceled, err)
+		t.FailNow()
+	}
+	// the aborted conversion was clocked out along with its gain pulses
+	if dtp.countL != dtp.countH || dtp.countL != 24+int(Gain64) {
+		t.Logf("expected %d ticks but tick was called %d times for High and %d times for Low", 24+int(Gain64), dtp.countH, dtp.countL)
+		t.FailNow()
+	}
+	if diag := td.Diagnostics(); diag.FailedConversions != 1 || !errors.Is(diag.LastError, context.Canceled) {
+		t.Logf("expected the aborted conversion in diagnostics but got %+v", diag)
+		t.FailNow()
+	}
+	ctx, cancel = context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	v, err := td.ReadContext(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if v != 2000 {
+		t.Logf("expected the next read to be clean and return %d but got %d", 2000, v)
+		t.FailNow()
+	}
+}
+
+func TestDevice_ReadDeadline(t *testing.T) {
+	dtp := &counterDataPin{}
+	dtp.loadBits([]uint32{1100}, false)
+	dtp.loadReady()
+	clk := newFakeClock()
+	td := Device{
+		sck:             dtp,
+		dt:              dtp,
+		initialized:     true,
+		gain:            Gain128,
+		smoothingFactor: 1,
+		offset:          100,
+		clk:             clk,
+	}
+	if _, err := td.ReadDeadline(clk.Now().Add(-time.Second)); !errors.Is(err, ErrTimeout) || !errors.Is(err, context.DeadlineExceeded) {
+		t.Logf("expected %v for a past deadline but got %v", ErrTimeout, err)
+		t.FailNow()
+	}
+	if dtp.countH != 0 || dtp.getIdx != 0 {
+		t.Log("expected the chip to be left alone with a past deadline")
+		t.FailNow()
+	}
+	v, err := td.ReadDeadline(clk.Now().Add(time.Minute))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if v != 1000 {
+		t.Logf("expected %d but got %d", 1000, v)
+		t.FailNow()
+	}
+
+	// the deadline is on the clock of the device too
+	dtp = &counterDataPin{get: []bool{true}, loop: true}
+	td.sck, td.dt = dtp, dtp
+	err = expireAfter(clk, time.Hour, func() error {
+		_, err := td.ReadDeadline(clk.Now().Add(time.Hour))
+		return err
+	})
+	if !errors.Is(err, ErrTimeout) {
+		t.Logf("expected %v but got %v", ErrTimeout, err)
+		t.FailNow()
+	}
+
+	td.initialized = false
+	if _, err := td.ReadDeadline(clk.Now().Add(-time.Second)); !errors.Is(err, ErrNotInitialized) {
+		t.Logf("expected %v for an uninitialized device but got %v", ErrNotInitialized, err)
+		t.FailNow()
+	}
+}
+
+func TestDevice_ReadOnTrigger(t *testing.T) {
+	dtp := &counterDataPin{}
+	dtp.loadBits([]uint32{1100}, false)
+	td := &Device{
+		sck:             dtp,
+		dt:              dtp,
+		gain:            Gain128,
+		smoothingFactor: 1,
+		offset:          100,
+		initialized:     true,
+		clk:             newFakeClock(),
+	}
+	trigger := make(chan struct{})
+	type result struct {
+		v   int64
+		err error
+	}
+	results := make(chan result)
+	go func() {
+		v, err := td.ReadOnTrigger(trigger, time.Second)
+		results <- result{v: v, err: err}
+	}()
+	time.Sleep(10 * time.Millisecond)
+	select {
+	case r := <-results:
+		t.Logf("expected the read to wait for the trigger but got %+v", r)
+		t.FailNow()
+	default:
+	}
+	trigger <- struct{}{}
+	r := <-results
+	if r.err != nil || r.v != 1000 {
+		t.Logf("expected %d but got %d and %v", 1000, r.v, r.err)
+		t.FailNow()
+	}
+	if dtp.getIdx != len(dtp.get) {
+		t.Logf("expected %d bits to be read after the trigger but %d were", len(dtp.get), dtp.getIdx)
+		t.FailNow()
+	}
+}
+
+func TestDevice_ReadOnTrigger_timeout(t *testing.T) {
+	dtp := &counterDataPin{}
+	clk := newFakeClock()
+	td := &Device{
+		sck:         dtp,
+		dt:          dtp,
+		gain:        Gain128,
+		initialized: true,
+		clk:         clk,
+	}
+	errs := make(chan error)
+	go func() {
+		_, err := td.ReadOnTrigger(make(chan struct{}), time.Second)
+		errs <- err
+	}()
+	for clk.Waiters() == 0 {
+		time.Sleep(time.Millisecond)
+	}
+	clk.Advance(time.Second)
+	if err := <-errs; !errors.Is(err, ErrTimeout) {
+		t.Logf("expected %v but got %v", ErrTimeout, err)
+		t.FailNow()
+	}
+	if dtp.countH != 0 {
+		t.Logf("expected no read without a trigger but tick was called %d times", dtp.countH)
+		t.FailNow()
+	}
+}
+
+// expireAfter runs op in the background and, once it waits on clk, advances clk by timeout, it returns the error
+// of op. Waiters left behind by earlier operations that finished in time are not taken for that of op.
+func expireAfter(clk *fakeClock, timeout time.Duration, op func() error) error {
+	waiting := clk.Waiters()
+	errs := make(chan error)
+	go func() {
+		errs <- op()
+	}()
+	for clk.Waiters() == waiting {
+		time.Sleep(time.Millisecond)
+	}
+	clk.Advance(timeout)
+	return <-errs
+}
+
+func TestDevice_SetReadTimeout(t *testing.T) {
+	// DT never goes low, the chip is never ready
+	dtp := &counterDataPin{get: []bool{true}, loop: true}
+	clk := newFakeClock()
+	td := Device{
+		sck:             dtp,
+		dt:              dtp,
+		initialized:     true,
+		gain:            Gain128,
+		smoothingFactor: 2,
+		offset:          100,
+		lastSample:      1100,
+		clk:             clk,
+	}
+	// the timeout is on the clock of the device, the wall clock would never get there during the test
+	td.SetReadTimeout(time.Hour)
+	var v int64
+	expireAfter(clk, time.Hour, func() error {
+		v = td.Read()
+		return nil
+	})
+	if v != 1000 {
+		t.Logf("expected the last value %d but got %d", 1000, v)
+		t.FailNow()
+	}
+	if err := td.Diagnostics().LastError; !errors.Is(err, ErrNoSensor) || !errors.Is(err, context.DeadlineExceeded) {
+		t.Logf("expected ErrNoSensor but got %v", err)
+		t.FailNow()
+	}
+	err := expireAfter(clk, time.Hour, func() error {
+		_, err := td.ReadContext(context.Background())
+		return err
+	})
+	if !errors.Is(err, ErrNoSensor) {
+		t.Logf("expected ErrNoSensor but got %v", err)
+		t.FailNow()
+	}
+	if dtp.countH != 0 {
+		t.Logf("expected no clock pulses but tick was called %d times", dtp.countH)
+		t.FailNow()
+	}
+
+	// the deadline of the context wins over the timeout of the device
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if _, err := td.ReadContext(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Logf("expected the context deadline but got %v", err)
+		t.FailNow()
+	}
+}
+
+func TestDevice_SetReadTimeout_waits(t *testing.T) {
+	// DT never goes low, the chip is never ready
+	dtp := &counterDataPin{get: []bool{true}, loop: true}
+	clk := newFakeClock()
+	td := Device{sck: dtp, dt: dtp, gain: Gain128, smoothingFactor: 1, clk: clk}
+	td.SetReadTimeout(time.Hour)
+	if err := expireAfter(clk, time.Hour, td.Initialize); !errors.Is(err, ErrNoSensor) {
+		t.Logf("expected Initialize to give up with ErrNoSensor but got %v", err)
+		t.FailNow()
+	}
+	if td.initialized {
+		t.Logf("expected the device to be left uninitialized")
+		t.FailNow()
+	}
+	td.initialized = true
+	if err := expireAfter(clk, time.Hour, td.VerifyRelease); !errors.Is(err, ErrNoSensor) {
+		t.Logf("expected VerifyRelease to give up with ErrNoSensor but got %v", err)
+		t.FailNow()
+	}
+	td.diag.LastError = nil
+	var ok bool
+	expireAfter(clk, time.Hour, func() error {
+		_, ok = td.lowPowerRead()
+		return nil
+	})
+	if ok || !errors.Is(td.Diagnostics().LastError, ErrNoSensor) {
+		t.Logf("expected no value and ErrNoSensor but got %v, %v", ok, td.Diagnostics().LastError)
+		t.FailNow()
+	}
+}
+
+func TestDevice_ReadOnChange(t *testing.T) {
+	clk := newFakeClock()
+	dtp := &counterDataPin{}
+	dtp.loadBits([]uint32{1100, 1102, 1101, 1103, 1600}, false)
+	td := Device{
+		sck:             dtp,
+		dt:              dtp,
+		initialized:     true,
+		gain:            Gain128,
+		smoothingFactor: 1,
+		offset:          100,
+		tickDelay:       time.Microsecond,
+		clk:             clk,
+	}
+	// the first read is always reported
+	if v, err := td.ReadOnChange(10, time.Second); err != nil || v != 1000 {
+		t.Logf("expected %d but got %d and %v", 1000, v, err)
+		t.FailNow()
+	}
+	if v, err := td.ReadOnChange(10, time.Second); err != nil || v != 1500 {
+		t.Logf("expected %d but got %d and %v", 1500, v, err)
+		t.FailNow()
+	}
+	if dtp.getIdx != 5*24 {
+		t.Logf("expected the jump to be found in the 5th read but %d bits were read", dtp.getIdx)
+		t.FailNow()
+	}
+	// from here on the value stays within threshold
+	dtp.loadBits([]uint32{1605}, true)
+	dtp.loop = true
+	if _, err := td.ReadOnChange(10, time.Millisecond); !errors.Is(err, ErrTimeout) {
+		t.Logf("expected ErrTimeout but got %v", err)
+		t.FailNow()
+	}
+}
@@ -0,0 +1,25 @@
+package hx711
+
+// Diagnostics holds information about what the driver actually did on the wire, it is meant to help
+// tracking down clones and wiring issues, combine it with SetTracer for the full picture.
+type Diagnostics struct {
+	// GainPulses is the number of pulses sent after the 24 data bits in the last read, these select
+	// gain and channel for the next conversion (1 for Gain128, 2 for Gain64 and 3 for Gain32).
+	GainPulses int
+	// StatusCheckFailures counts the reads whose status bits did not pass the check passed to SetStatusBits.
+	StatusCheckFailures int
+	// FailedConversions counts the conversions a Sampler failed to provide.
+	FailedConversions int
+	// Conversions is the number of conversions averaged by the last read, failed ones are left out of it when
+	// SetMinSamples is used.
+	Conversions int
+	// LastError is the error of the last failed conversion.
+	LastError error
+}
+
+// Diagnostics returns a snapshot of the diagnostic counters.
+func (d *Device) Diagnostics() Diagnostics {
+	d.opMutex.Lock()
+	defer d.opMutex.Unlock()
+	return d.diag
+}
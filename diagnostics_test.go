@@ -0,0 +1,26 @@
+package hx711
+
+import "testing"
+
+func TestDevice_Diagnostics_GainPulses(t *testing.T) {
+	for _, g := range []gainLVL{Gain128, Gain64, Gain32} {
+		dtp := &counterDataPin{}
+		dtp.loadBits([]uint32{50000, 50001}, false)
+		td := Device{
+			sck:             dtp,
+			dt:              dtp,
+			initialized:     true,
+			gain:            g,
+			smoothingFactor: 2,
+		}
+		if p := td.Diagnostics().GainPulses; p != 0 {
+			t.Logf("expected no gain pulses before a read but got %d", p)
+			t.FailNow()
+		}
+		td.Read()
+		if p := td.Diagnostics().GainPulses; p != int(g) {
+			t.Logf("Gain is %d but diagnostics report %d gain pulses", g, p)
+			t.FailNow()
+		}
+	}
+}
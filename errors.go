@@ -0,0 +1,64 @@
+package hx711
+
+import (
+	"context"
+	"errors"
+)
+
+// Errors returned by Device methods, they are wrapped with more details so use errors.Is to check for them.
+var (
+	// ErrTimeout is returned when an operation did not finish in the time it was given.
+	ErrTimeout = errors.New("hx711: timed out")
+	// ErrSaturated is returned when a read hit the top or bottom of the chip's range, the value is still
+	// returned but it is not the real weight.
+	ErrSaturated = errors.New("hx711: reading is saturated")
+	// ErrNoSensor is returned when waiting for the chip timed out and DT was never seen low, usually the
+	// chip is not connected or not powered.
+	ErrNoSensor = errors.New("hx711: no sensor responded")
+	// ErrNotCalibrated is returned by operations that need a calibration factor when none was set.
+	ErrNotCalibrated = errors.New("hx711: device is not calibrated")
+	// ErrZeroWeight is returned when calibrating against a weight of 0, or from the rating of a cell with a
+	// capacity, sensitivity or excitation of 0.
+	ErrZeroWeight = errors.New("hx711: weight needs to be > 0")
+	// ErrZeroFactor is returned when a calibration would result in a factor of 0.
+	ErrZeroFactor = errors.New("hx711: resulting calibration factor would be 0")
+	// ErrNotReleased is returned by VerifyRelease when the chip does not release DT after a read.
+	ErrNotReleased = errors.New("hx711: DT not released after the read")
+	// ErrNotInitialized is returned when reading from a Device that was not initialized, ie: not created with
+	// New or NewWithOptions.
+	ErrNotInitialized = errors.New("hx711: device is not initialized")
+	// ErrClockStretched is returned when a clock pulse took longer than allowed, see SetMaxPulseWidth.
+	ErrClockStretched = errors.New("hx711: clock pulse stretched")
+	// ErrWrongIdleState is returned by StartupCheck when DT does not idle at the expected level.
+	ErrWrongIdleState = errors.New("hx711: DT is not at the expected idle level")
+	// ErrTooFewSamples is returned when less conversions than the minimum set with SetMinSamples succeeded.
+	ErrTooFewSamples = errors.New("hx711: too few conversions succeeded")
+	// ErrNoRead is returned by operations that use the last read when there was none yet.
+	ErrNoRead = errors.New("hx711: no read has been performed yet")
+	// ErrTooFewPoints is returned when fitting a calibration with less than 2 points of different readings.
+	ErrTooFewPoints = errors.New("hx711: not enough calibration points")
+	// ErrBadState is returned by LoadState when the data was not written by SaveState.
+	ErrBadState = errors.New("hx711: invalid saved state")
+)
+
+// ctxError is a context error that also matches one of our errors with errors.Is.
+type ctxError struct {
+	sentinel error
+	err      error
+}
+
+func (e ctxError) Error() string        { return e.sentinel.Error() + ": " + e.err.Error() }
+func (e ctxError) Unwrap() error        { return e.err }
+func (e ctxError) Is(target error) bool { return target == e.sentinel }
+
+// contextError turns a deadline into ErrTimeout or ErrNoSensor, depending on whether the chip was ever seen
+// ready, other errors are returned as they are.
+func (d *Device) contextError(err error) error {
+	if !errors.Is(err, context.DeadlineExceeded) {
+		return err
+	}
+	if !d.seenReady {
+		return ctxError{sentinel: ErrNoSensor, err: err}
+	}
+	return ctxError{sentinel: ErrTimeout, err: err}
+}
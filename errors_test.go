@@ -0,0 +1,193 @@
+package hx711
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		want error
+		run  func() error
+	}{
+		{
+			name: "calibrate with 0 weight",
+			want: ErrZeroWeight,
+			run: func() error {
+				_, err := NewPreset(CalibrationState{}).Calibrate(0)
+				return err
+			},
+		},
+		{
+			name: "calibrate with no load",
+			want: ErrZeroFactor,
+			run: func() error {
+				dtp := &counterDataPin{}
+				dtp.loadBits([]uint32{1000}, false)
+				td := Device{initialized: true, sck: dtp, dt: dtp, gain: Gain128, smoothingFactor: 1, offset: 1000}
+				_, err := td.Calibrate(100)
+				return err
+			},
+		},
+		{
+			name: "calibrate fixed with 0 weight",
+			want: ErrZeroWeight,
+			run: func() error {
+				return NewPreset(CalibrationState{}).CalibrateFixed(0)
+			},
+		},
+		{
+			name: "calibrate fixed with no load",
+			want: ErrZeroFactor,
+			run: func() error {
+				dtp := &counterDataPin{}
+				dtp.loadBits([]uint32{1000}, false)
+				td := Device{initialized: true, sck: dtp, dt: dtp, gain: Gain128, smoothingFactor: 1, offset: 1000}
+				return td.CalibrateFixed(100)
+			},
+		},
+		{
+			name: "calibration ratio of 0",
+			want: ErrZeroFactor,
+			run: func() error {
+				return NewPreset(CalibrationState{}).SetCalibrationRatio(0, 1)
+			},
+		},
+		{
+			name: "fit a flat calibration",
+			want: ErrZeroFactor,
+			run: func() error {
+				dtp := &counterDataPin{}
+				dtp.loadBits([]uint32{1000, 2000}, false)
+				td := Device{initialized: true, sck: dtp, dt: dtp, gain: Gain128, smoothingFactor: 1}
+				td.AddCalibrationPoint(10)
+				td.AddCalibrationPoint(10)
+				_, err := td.FitCalibration()
+				return err
+			},
+		},
+		{
+			name: "fit a single point",
+			want: ErrTooFewPoints,
+			run: func() error {
+				dtp := &counterDataPin{}
+				dtp.loadBits([]uint32{1000}, false)
+				td := Device{initialized: true, sck: dtp, dt: dtp, gain: Gain128, smoothingFactor: 1}
+				td.AddCalibrationPoint(10)
+				_, err := td.FitCalibration()
+				return err
+			},
+		},
+		{
+			name: "fit points of the same reading",
+			want: ErrTooFewPoints,
+			run: func() error {
+				dtp := &counterDataPin{}
+				dtp.loadBits([]uint32{1000, 1000}, false)
+				td := Device{initialized: true, sck: dtp, dt: dtp, gain: Gain128, smoothingFactor: 1}
+				td.AddCalibrationPoint(10)
+				td.AddCalibrationPoint(20)
+				_, err := td.FitCalibration()
+				return err
+			},
+		},
+		{
+			name: "configure from a rating of 0",
+			want: ErrZeroWeight,
+			run: func() error {
+				_, err := NewPreset(CalibrationState{}).ConfigureFromRating(0, 2, 5, Gain128)
+				return err
+			},
+		},
+		{
+			name: "tare from the last read without reads",
+			want: ErrNoRead,
+			run: func() error {
+				return NewPreset(CalibrationState{}).TareFromLast()
+			},
+		},
+		{
+			name: "quality without a fit",
+			want: ErrNotCalibrated,
+			run: func() error {
+				_, err := NewPreset(CalibrationState{}).CalibrationQuality()
+				return err
+			},
+		},
+		{
+			name: "get tare in grams without calibration",
+			want: ErrNotCalibrated,
+			run: func() error {
+				_, err := NewPreset(CalibrationState{}).GetTareGrams()
+				return err
+			},
+		},
+		{
+			name: "set tare in grams without calibration",
+			want: ErrNotCalibrated,
+			run: func() error {
+				return NewPreset(CalibrationState{}).SetTareGrams(10)
+			},
+		},
+		{
+			name: "weight never stabilizes",
+			want: ErrTimeout,
+			run: func() error {
+				dtp := &counterDataPin{loop: true}
+				dtp.loadBits([]uint32{1000, 1300}, false)
+				td := Device{initialized: true, sck: dtp, dt: dtp, gain: Gain128, smoothingFactor: 1, calibrationFactor: 1}
+				_, err := td.WeighStable(2, 3, time.Millisecond)
+				return err
+			},
+		},
+		{
+			name: "chip never ready",
+			want: ErrNoSensor,
+			run: func() error {
+				dtp := &counterDataPin{loop: true, get: []bool{true}}
+				td := Device{sck: dtp, dt: dtp, gain: Gain128, smoothingFactor: 1, initialized: true}
+				ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond)
+				defer cancel()
+				_, err := td.ReadContext(ctx)
+				return err
+			},
+		},
+		{
+			name: "chip stops being ready",
+			want: ErrTimeout,
+			run: func() error {
+				dtp := &counterDataPin{loop: true, get: []bool{true}}
+				td := Device{sck: dtp, dt: dtp, gain: Gain128, smoothingFactor: 1, seenReady: true, initialized: true}
+				ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond)
+				defer cancel()
+				_, err := td.ReadContext(ctx)
+				return err
+			},
+		},
+		{
+			name: "saturated read",
+			want: ErrSaturated,
+			run: func() error {
+				dtp := &counterDataPin{}
+				dtp.loadBits([]uint32{saturatedHigh}, false)
+				dtp.loadReady()
+				td := Device{sck: dtp, dt: dtp, gain: Gain128, smoothingFactor: 1, initialized: true}
+				ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+				defer cancel()
+				_, err := td.ReadContext(ctx)
+				return err
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.run(); !errors.Is(err, tt.want) {
+				t.Logf("expected %v but got %v", tt.want, err)
+				t.FailNow()
+			}
+		})
+	}
+}
@@ -0,0 +1,397 @@
+package hx711
+
+import (
+	"math"
+	"sort"
+)
+
+// SmoothingMode selects how the conversions of a read are combined.
+type SmoothingMode int
+
+const (
+	// SmoothingRunning averages each conversion with the running value, so later conversions weigh more, this
+	// is the default and how this always worked.
+	SmoothingRunning SmoothingMode = iota
+	// SmoothingMean is the arithmetic mean of the conversions, accumulated in 64 bits so it can't overflow.
+	SmoothingMean
+	// SmoothingMedian takes the median of the conversions and averages those that are not outliers of it, a run
+	// of bad conversions can't drag it along like it does with consecutive comparisons.
+	SmoothingMedian
+	// SmoothingRecency is a weighted mean of the conversions where each one weighs decay times the one after
+	// it, so later conversions count more, see SetRecencyDecay. Unlike SetLowPass nothing carries over between
+	// reads.
+	SmoothingRecency
+)
+
+// RoundingMode selects how the integer division of SmoothingMean and SmoothingMedian rounds.
+type RoundingMode int
+
+const (
+	// RoundTruncate drops the fraction, rounding towards 0, this is the default.
+	RoundTruncate RoundingMode = iota
+	// RoundHalfUp rounds to the nearest value, halves go up (towards +∞).
+	RoundHalfUp
+	// RoundHalfEven rounds to the nearest value, halves go to the even neighbor (banker's rounding).
+	RoundHalfEven
+)
+
+// divide returns sum/count rounded with mode, count must be > 0.
+func (mode RoundingMode) divide(sum, count int64) int64 {
+	q, r := sum/count, sum%count
+	if mode == RoundTruncate || r == 0 {
+		return q
+	}
+	// make it a floor division so r is in [0, count)
+	if r < 0 {
+		q--
+		r += count
+	}
+	switch {
+	case 2*r > count:
+		return q + 1
+	case 2*r < count:
+		return q
+	case mode == RoundHalfUp || q%2 != 0:
+		return q + 1
+	}
+	return q
+}
+
+// defaultRecencyDecay is the decay of SmoothingRecency until SetRecencyDecay is called.
+const defaultRecencyDecay = 0.5
+
+// avgConfig tunes how the reads of a burst are averaged, the zero value is the plain avg behavior.
+type avgConfig struct {
+	// earlyExitRun, if > 0, ends the burst once that many consecutive reads are within
+	// earlyExitTolerance of each other.
+	earlyExitRun       int
+	earlyExitTolerance int64
+	// outlierPercent, if > 0, rejects reads that differ from the running value by more than that percentage
+	// of it instead of using the fixed threshold.
+	outlierPercent float64
+	// keepOutliers averages every read, see ReadOpts
+	keepOutliers bool
+	mode         SmoothingMode
+	rounding     RoundingMode
+	// decay is the weight of each conversion relative to the next one in SmoothingRecency
+	decay float64
+	// observe, if not nil, is passed each decision average takes, see SetOutlierWindow
+	observe func(step avgStep)
+}
+
+// avgStep is what average decided about one conversion.
+type avgStep struct {
+	// value is the conversion, sign extended
+	value int64
+	// rejected is whether it was left out as an outlier
+	rejected bool
+	// running is the value of the average after the decision, for SmoothingMedian it is the median the
+	// conversions are compared against
+	running int64
+}
+
+// seen passes the decision about a conversion to observe.
+func (cfg avgConfig) seen(value int64, rejected bool, running int64) {
+	if cfg.observe != nil {
+		cfg.observe(avgStep{value: value, rejected: rejected, running: running})
+	}
+}
+
+// outlierThreshold is the fixed difference, in raw units, between consecutive reads above which a read is
+// taken as an outlier.
+const outlierThreshold = 100
+
+// isOutlier reports whether rr is too far from the running value pr to be averaged.
+func (cfg avgConfig) isOutlier(rr, pr uint32) bool {
+	if cfg.keepOutliers {
+		return false
+	}
+	if cfg.outlierPercent > 0 {
+		running := toInt64(pr)
+		return float64(abs64(toInt64(rr)-running)) > float64(abs64(running))*cfg.outlierPercent/100
+	}
+	return (rr - pr) > outlierThreshold
+}
+
+// isOutlierValue is isOutlier for sign extended values.
+func (cfg avgConfig) isOutlierValue(v, running int64) bool {
+	if cfg.keepOutliers {
+		return false
+	}
+	if cfg.outlierPercent > 0 {
+		return float64(abs64(v-running)) > float64(abs64(running))*cfg.outlierPercent/100
+	}
+	return abs64(v-running) > outlierThreshold
+}
+
+// average performs a burst of <times> reads discarding outliers and returns the average.
+func average(times int, f func() uint32, cfg avgConfig) uint32 {
+	r, _ := averageOf(times, func() (uint32, error) { return f(), nil }, cfg)
+	return r
+}
+
+// averageOf is average for reads that can fail, the failed ones are left out as if they never happened, it
+// also returns how many reads did not fail, if none did the average is 0.
+func averageOf(times int, f func() (uint32, error), cfg avgConfig) (uint32, int) {
+	var r uint32
+	var sum, count int64
+	var weighted, weights float64
+	var window []int64
+	var previous int64
+	run, ok := 0, 0
+	for attempt := 0; attempt < times; attempt++ {
+		rr, err := f()
+		if err != nil {
+			continue
+		}
+		if cfg.earlyExitRun > 0 {
+			current := toInt64(rr)
+			if ok > 0 && abs64(current-previous) <= cfg.earlyExitTolerance {
+				run++
+			} else {
+				run = 1
+			}
+			previous = current
+		}
+		switch cfg.mode {
+		case SmoothingMedian:
+			window = append(window, toInt64(rr))
+		case SmoothingRecency:
+			v := toInt64(rr)
+			rejected := weights != 0 && cfg.isOutlierValue(v, int64(math.Round(weighted/weights)))
+			if !rejected {
+				decay := cfg.decay
+				if decay == 0 {
+					decay = defaultRecencyDecay
+				}
+				weighted = weighted*decay + float64(v)
+				weights = weights*decay + 1
+			}
+			cfg.seen(v, rejected, int64(math.Round(weighted/weights)))
+		case SmoothingMean:
+			// a uint32 sum of 24 bit values overflows after 256 of them
+			v := toInt64(rr)
+			rejected := count != 0 && cfg.isOutlierValue(v, sum/count)
+			if !rejected {
+				sum += v
+				count++
+			}
+			cfg.seen(v, rejected, cfg.rounding.divide(sum, count))
+		default:
+			pr := r
+			r += rr
+			rejected := false
+			if ok > 0 {
+				// this is a burst of N reads, if the two consecutive reads are too dissimilar we discard it as an outlier
+				// which at least in my chip happens a lot.
+				if rejected = cfg.isOutlier(rr, pr); rejected {
+					r = pr
+				} else {
+					r = r / 2
+				}
+			}
+			cfg.seen(toInt64(rr), rejected, toInt64(r))
+		}
+		ok++
+		if cfg.earlyExitRun > 0 && run >= cfg.earlyExitRun {
+			break
+		}
+	}
+	switch cfg.mode {
+	case SmoothingMedian:
+		return uint32(cfg.medianMean(window)) & 0xFFFFFF, ok
+	case SmoothingRecency:
+		if weights == 0 {
+			return 0, ok
+		}
+		return uint32(int64(math.Round(weighted/weights))) & 0xFFFFFF, ok
+	case SmoothingMean:
+		if count == 0 {
+			return 0, ok
+		}
+		return uint32(cfg.rounding.divide(sum, count)) & 0xFFFFFF, ok
+	}
+	return r, ok
+}
+
+// medianMean returns the mean of the values that are not outliers of their median.
+func (cfg avgConfig) medianMean(values []int64) int64 {
+	if len(values) == 0 {
+		return 0
+	}
+	sorted := append([]int64(nil), values...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+	median := sorted[len(sorted)/2]
+	var sum, count int64
+	for _, v := range values {
+		rejected := cfg.isOutlierValue(v, median)
+		cfg.seen(v, rejected, median)
+		if rejected {
+			continue
+		}
+		sum += v
+		count++
+	}
+	// count can't be 0, the median itself is always kept
+	return cfg.rounding.divide(sum, count)
+}
+
+// SetRecencyDecay sets how much each conversion weighs relative to the one after it in SmoothingRecency, in
+// (0, 1], the lower the more the latest conversions dominate, 1 is a plain mean. Defaults to 0.5.
+func (d *Device) SetRecencyDecay(decay float64) {
+	d.opMutex.Lock()
+	defer d.opMutex.Unlock()
+	if decay <= 0 || decay > 1 {
+		decay = defaultRecencyDecay
+	}
+	d.smoothing.decay = decay
+}
+
+// SetRoundingMode selects how SmoothingMean and SmoothingMedian round the average of the conversions, which
+// some regulatory contexts care about, defaults to RoundTruncate.
+func (d *Device) SetRoundingMode(mode RoundingMode) {
+	d.opMutex.Lock()
+	defer d.opMutex.Unlock()
+	d.smoothing.rounding = mode
+}
+
+// SetSmoothingMode selects how the conversions of each read are combined, defaults to SmoothingRunning.
+func (d *Device) SetSmoothingMode(m SmoothingMode) {
+	d.opMutex.Lock()
+	defer d.opMutex.Unlock()
+	d.smoothing.mode = m
+}
+
+// SetOutlierPercent makes reads of a burst be rejected as outliers when they differ from the running average
+// by more than p percent of it, rather than by a fixed amount, which adapts better to the whole range of the
+// cell. Pass 0 to go back to the fixed threshold.
+func (d *Device) SetOutlierPercent(p float64) {
+	d.opMutex.Lock()
+	defer d.opMutex.Unlock()
+	if p < 0 {
+		p = 0
+	}
+	d.smoothing.outlierPercent = p
+}
+
+// SetEarlyExit makes reads stop the burst before <SmoothingFactor> reads once minSamples consecutive reads
+// agree within tolerance, which cuts latency a lot on a quiet signal. Pass a minSamples of 0 to disable it.
+func (d *Device) SetEarlyExit(tolerance int64, minSamples int) {
+	d.opMutex.Lock()
+	defer d.opMutex.Unlock()
+	if minSamples < 0 {
+		minSamples = 0
+	}
+	d.smoothing.earlyExitRun = minSamples
+	d.smoothing.earlyExitTolerance = tolerance
+}
+
+// lowPass is a first order IIR low-pass filter, an alpha of 0 means disabled.
+type lowPass struct {
+	alpha  float64
+	value  float64
+	primed bool
+}
+
+// apply feeds v to the filter and returns the filtered value.
+func (l *lowPass) apply(v int64) int64 {
+	if l.alpha == 0 {
+		return v
+	}
+	if !l.primed {
+		// start from the first value instead of 0 so we don't ramp up from nothing
+		l.value = float64(v)
+		l.primed = true
+		return v
+	}
+	l.value += l.alpha * (float64(v) - l.value)
+	return int64(math.Round(l.value))
+}
+
+// lowPassAlpha returns the smoothing coefficient of a first order low-pass with the passed cutoff for samples
+// taken every interval seconds.
+func lowPassAlpha(cutoffHz, interval float64) float64 {
+	rc := 1 / (2 * math.Pi * cutoffHz)
+	return interval / (rc + interval)
+}
+
+// SetSampleRate tells the driver the conversion rate of the chip in Hz, 10 or 80 depending on the RATE pin,
+// defaults to DefaultSampleRate. It is used to compute filter coefficients so set it before SetLowPass.
+func (d *Device) SetSampleRate(hz float64) {
+	d.opMutex.Lock()
+	defer d.opMutex.Unlock()
+	if hz <= 0 {
+		hz = DefaultSampleRate
+	}
+	d.sampleRate = hz
+}
+
+// SetLowPass applies a first order low-pass filter with the passed cutoff in Hz to the results of Read and
+// ReadCalibrated, pass 0 to disable it.
+// Each read averages <SmoothingFactor> conversions so the filter runs at sample rate / smoothing factor, which
+// means the cutoff should be well below that or it will do nothing.
+func (d *Device) SetLowPass(cutoffHz float64) {
+	d.opMutex.Lock()
+	defer d.opMutex.Unlock()
+	if cutoffHz <= 0 {
+		d.lowPass = lowPass{}
+		return
+	}
+	rate := d.sampleRate
+	if rate <= 0 {
+		rate = DefaultSampleRate
+	}
+	conversions := d.smoothingFactor
+	if conversions < 1 {
+		conversions = 1
+	}
+	d.lowPass = lowPass{alpha: lowPassAlpha(cutoffHz, float64(conversions)/rate)}
+}
+
+// maxMainsSamples is the most conversions ReadRejectMains averages, when no count up to it spans whole mains
+// periods the closest to a single period is used.
+const maxMainsSamples = 256
+
+// mainsSamples returns the smallest amount of conversions at rate that span a whole number of periods of a
+// mains frequency of hz, so the hum averages out.
+func mainsSamples(rate, hz float64) int {
+	for n := 1; n <= maxMainsSamples; n++ {
+		periods := float64(n) * hz / rate
+		if math.Abs(periods-math.Round(periods)) < 1e-6 && math.Round(periods) >= 1 {
+			return n
+		}
+	}
+	n := int(math.Round(rate / hz))
+	if n < 1 {
+		n = 1
+	}
+	return n
+}
+
+// ReadRejectMains reads like Read but averages exactly as many conversions as span a whole number of periods of
+// the mains frequency hz (50 or 60), given the sample rate set with SetSampleRate, so hum coupled into the cell
+// cancels out, ie: at 80Hz that is 8 conversions for 50Hz and 4 for 60Hz. Every conversion weighs the same and
+// none is rejected as an outlier, otherwise the hum would not cancel. It does not go through the low-pass filter.
+// A hz of 0 averages <SmoothingFactor> conversions.
+func (d *Device) ReadRejectMains(hz float64) int64 {
+	d.opMutex.Lock()
+	defer d.opMutex.Unlock()
+	if d.notInitialized() {
+		return 0
+	}
+	rate := d.sampleRate
+	if rate <= 0 {
+		rate = DefaultSampleRate
+	}
+	n := d.smoothingFactor
+	if hz > 0 {
+		n = mainsSamples(rate, hz)
+	}
+	cfg := d.smoothing
+	cfg.mode = SmoothingMean
+	cfg.keepOutliers = true
+	cfg.earlyExitRun = 0
+	d.discardPending()
+	d.saturated = false
+	return toInt64(average(n, d.conversion, cfg)) - d.offset - d.tare
+}
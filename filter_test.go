@@ -0,0 +1,466 @@
+package hx711
+
+import (
+	"fmt"
+	"math"
+	"testing"
+)
+
+func Test_lowPassAlpha(t *testing.T) {
+	// 1Hz cutoff sampled at 10Hz: rc = 1/2π, dt = 0.1
+	want := 0.1 / (1/(2*math.Pi) + 0.1)
+	if got := lowPassAlpha(1, 0.1); fmt.Sprintf("%.10f", got) != fmt.Sprintf("%.10f", want) {
+		t.Logf("expected alpha to be %.10f but is %.10f", want, got)
+		t.FailNow()
+	}
+	td := Device{smoothingFactor: 1, sampleRate: 10}
+	td.SetLowPass(1)
+	if fmt.Sprintf("%.10f", td.lowPass.alpha) != fmt.Sprintf("%.10f", want) {
+		t.Logf("expected device alpha to be %.10f but is %.10f", want, td.lowPass.alpha)
+		t.FailNow()
+	}
+	td.SetLowPass(0)
+	if td.lowPass.alpha != 0 {
+		t.Logf("expected low pass to be disabled but alpha is %f", td.lowPass.alpha)
+		t.FailNow()
+	}
+}
+
+func TestDevice_SetLowPass_stepResponse(t *testing.T) {
+	dtp := &counterDataPin{}
+	bits := []uint32{1000}
+	for i := 0; i < 20; i++ {
+		bits = append(bits, 2000)
+	}
+	dtp.loadBits(bits, false)
+	td := Device{
+		sck:             dtp,
+		dt:              dtp,
+		initialized:     true,
+		gain:            Gain128,
+		smoothingFactor: 1,
+		sampleRate:      10,
+	}
+	td.SetLowPass(1)
+	alpha := td.lowPass.alpha
+	if v := td.Read(); v != 1000 {
+		t.Logf("expected the first read to prime the filter with %d but got %d", 1000, v)
+		t.FailNow()
+	}
+	expected := 1000.0
+	last := int64(1000)
+	for i := 0; i < 20; i++ {
+		expected += alpha * (2000 - expected)
+		v := td.Read()
+		if v != int64(math.Round(expected)) {
+			t.Logf("step %d expected %d but got %d", i, int64(math.Round(expected)), v)
+			t.FailNow()
+		}
+		if v < last || v > 2000 {
+			t.Logf("step response should rise monotonically towards 2000 but went from %d to %d", last, v)
+			t.FailNow()
+		}
+		last = v
+	}
+	if last < 1990 {
+		t.Logf("expected the filter to have settled near 2000 but got %d", last)
+		t.FailNow()
+	}
+}
+
+func TestDevice_SetEarlyExit(t *testing.T) {
+	stable := make([]uint32, 10)
+	noisy := make([]uint32, 10)
+	for i := range stable {
+		stable[i] = 5000
+		noisy[i] = 5000 + uint32(i%2)*10
+	}
+	tests := []struct {
+		name  string
+		bits  []uint32
+		reads int
+	}{
+		{name: "stable", bits: stable, reads: 3},
+		{name: "noisy", bits: noisy, reads: 10},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dtp := &counterDataPin{}
+			dtp.loadBits(tt.bits, false)
+			td := Device{
+				sck:             dtp,
+				dt:              dtp,
+				initialized:     true,
+				gain:            Gain128,
+				smoothingFactor: 10,
+			}
+			td.SetEarlyExit(2, 3)
+			td.Read()
+			if dtp.countH != tt.reads*(24+int(Gain128)) {
+				t.Logf("expected %d reads but tick was called %d times", tt.reads, dtp.countH)
+				t.FailNow()
+			}
+		})
+	}
+}
+
+func Test_average_outlierPercent(t *testing.T) {
+	tests := []struct {
+		name    string
+		values  []uint32
+		percent float64
+		want    uint32
+	}{
+		{name: "small baseline fixed", values: []uint32{1000, 1050}, percent: 0, want: 1025},
+		{name: "small baseline percent", values: []uint32{1000, 1050}, percent: 1, want: 1000},
+		{name: "small baseline percent within", values: []uint32{1000, 1008}, percent: 1, want: 1004},
+		{name: "large baseline fixed", values: []uint32{1000000, 1005000}, percent: 0, want: 1000000},
+		{name: "large baseline percent", values: []uint32{1000000, 1005000}, percent: 1, want: 1002500},
+		{name: "large baseline percent outlier", values: []uint32{1000000, 1020000}, percent: 1, want: 1000000},
+		{name: "decreasing percent", values: []uint32{1000000, 995000}, percent: 1, want: 997500},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			i := 0
+			f := func() uint32 {
+				v := tt.values[i]
+				i++
+				return v
+			}
+			if got := average(len(tt.values), f, avgConfig{outlierPercent: tt.percent}); got != tt.want {
+				t.Logf("expected %d but got %d", tt.want, got)
+				t.FailNow()
+			}
+		})
+	}
+}
+
+func Test_average_meanOverflow(t *testing.T) {
+	tests := []struct {
+		name   string
+		values []uint32
+		want   int64
+	}{
+		// 1000 of these add up to way more than a uint32 holds
+		{name: "near full scale", values: []uint32{0x7FFFF0, 0x7FFFF0, 0x7FFFEE, 0x7FFFF2}, want: 0x7FFFF0},
+		{name: "near negative full scale", values: []uint32{0x800010, 0x800012, 0x80000E, 0x800010}, want: -0x7FFFF0},
+		{name: "around 0", values: []uint32{0xFFFFF6, 0x00000A, 0xFFFFF6, 0x00000A}, want: 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			i := 0
+			f := func() uint32 {
+				v := tt.values[i%len(tt.values)]
+				i++
+				return v
+			}
+			if got := toInt64(average(1000, f, avgConfig{mode: SmoothingMean})); got != tt.want {
+				t.Logf("expected %d but got %d", tt.want, got)
+				t.FailNow()
+			}
+			if i != 1000 {
+				t.Logf("expected %d reads but got %d", 1000, i)
+				t.FailNow()
+			}
+		})
+	}
+}
+
+func TestDevice_SetSmoothingMode(t *testing.T) {
+	dtp := &counterDataPin{}
+	dtp.loadBits([]uint32{1000, 1090, 1020, 5000}, false)
+	td := Device{
+		sck:             dtp,
+		dt:              dtp,
+		initialized:     true,
+		gain:            Gain128,
+		smoothingFactor: 4,
+	}
+	td.SetSmoothingMode(SmoothingMean)
+	// 5000 is an outlier of the mean of the rest
+	if v := td.Read(); v != 1036 {
+		t.Logf("expected %d but got %d", 1036, v)
+		t.FailNow()
+	}
+}
+
+func Test_average_median(t *testing.T) {
+	// the load is 1000 but the burst starts with a run of bad conversions
+	values := []uint32{1300, 1310, 1300, 1000, 1000, 1000, 1000, 1000, 1000}
+	f := func() func() uint32 {
+		i := 0
+		return func() uint32 {
+			v := values[i]
+			i++
+			return v
+		}
+	}
+	if got := toInt64(average(len(values), f(), avgConfig{})); got < 1300 {
+		t.Logf("expected consecutive comparisons to stick to the bad run but got %d", got)
+		t.FailNow()
+	}
+	if got := toInt64(average(len(values), f(), avgConfig{mode: SmoothingMedian})); got != 1000 {
+		t.Logf("expected %d but got %d", 1000, got)
+		t.FailNow()
+	}
+	// negative values are sorted as such
+	values = []uint32{0xFFFC18, 0xFFFC18, 0xFFFC18, 0x000000}
+	if got := toInt64(average(len(values), f(), avgConfig{mode: SmoothingMedian})); got != -1000 {
+		t.Logf("expected %d but got %d", -1000, got)
+		t.FailNow()
+	}
+}
+
+func Test_average_recency(t *testing.T) {
+	tests := []struct {
+		name  string
+		decay float64
+		want  int64
+	}{
+		// (1000*0.25 + 1050*0.5 + 1090) / 1.75
+		{name: "default", decay: 0, want: 1066},
+		// (1000*0.01 + 1050*0.1 + 1090) / 1.11
+		{name: "fast", decay: 0.1, want: 1086},
+		{name: "mean", decay: 1, want: 1047},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			values := []uint32{1000, 1050, 1090}
+			i := 0
+			f := func() uint32 {
+				v := values[i]
+				i++
+				return v
+			}
+			if got := toInt64(average(len(values), f, avgConfig{mode: SmoothingRecency, decay: tt.decay})); got != tt.want {
+				t.Logf("expected %d but got %d", tt.want, got)
+				t.FailNow()
+			}
+		})
+	}
+}
+
+func TestDevice_SetRecencyDecay(t *testing.T) {
+	dtp := &counterDataPin{}
+	dtp.loadBits([]uint32{1000, 1050, 1090}, false)
+	td := Device{
+		sck:             dtp,
+		dt:              dtp,
+		initialized:     true,
+		gain:            Gain128,
+		smoothingFactor: 3,
+	}
+	td.SetSmoothingMode(SmoothingRecency)
+	td.SetRecencyDecay(0.1)
+	if v := td.Read(); v != 1086 {
+		t.Logf("expected %d but got %d", 1086, v)
+		t.FailNow()
+	}
+}
+
+func TestRoundingMode_divide(t *testing.T) {
+	tests := []struct {
+		sum, count         int64
+		truncate, up, even int64
+	}{
+		{sum: 3, count: 2, truncate: 1, up: 2, even: 2},
+		{sum: 5, count: 2, truncate: 2, up: 3, even: 2},
+		{sum: -3, count: 2, truncate: -1, up: -1, even: -2},
+		{sum: -5, count: 2, truncate: -2, up: -2, even: -2},
+		{sum: 7, count: 3, truncate: 2, up: 2, even: 2},
+		{sum: 8, count: 3, truncate: 2, up: 3, even: 3},
+		{sum: -8, count: 3, truncate: -2, up: -3, even: -3},
+		{sum: 6, count: 3, truncate: 2, up: 2, even: 2},
+	}
+	for _, tt := range tests {
+		for mode, want := range map[RoundingMode]int64{RoundTruncate: tt.truncate, RoundHalfUp: tt.up, RoundHalfEven: tt.even} {
+			if got := mode.divide(tt.sum, tt.count); got != want {
+				t.Logf("mode %d expected %d/%d to be %d but got %d", mode, tt.sum, tt.count, want, got)
+				t.FailNow()
+			}
+		}
+	}
+}
+
+func TestDevice_SetRoundingMode(t *testing.T) {
+	tests := []struct {
+		name   string
+		mode   RoundingMode
+		values []uint32
+		want   int64
+	}{
+		{name: "truncate 2.5", mode: RoundTruncate, values: []uint32{1002, 1003}, want: 1002},
+		{name: "half up 2.5", mode: RoundHalfUp, values: []uint32{1002, 1003}, want: 1003},
+		{name: "half even 2.5", mode: RoundHalfEven, values: []uint32{1002, 1003}, want: 1002},
+		{name: "half even 3.5", mode: RoundHalfEven, values: []uint32{1003, 1004}, want: 1004},
+		{name: "truncate -2.5", mode: RoundTruncate, values: []uint32{0xFFFFFE, 0xFFFFFD}, want: -2},
+		{name: "half up -2.5", mode: RoundHalfUp, values: []uint32{0xFFFFFE, 0xFFFFFD}, want: -2},
+		{name: "half even -2.5", mode: RoundHalfEven, values: []uint32{0xFFFFFE, 0xFFFFFD}, want: -2},
+		{name: "half even -3.5", mode: RoundHalfEven, values: []uint32{0xFFFFFD, 0xFFFFFC}, want: -4},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dtp := &counterDataPin{}
+			dtp.loadBits(tt.values, false)
+			td := Device{
+				sck:             dtp,
+				dt:              dtp,
+				gain:            Gain128,
+				smoothingFactor: 2,
+				initialized:     true,
+			}
+			td.SetSmoothingMode(SmoothingMean)
+			td.SetRoundingMode(tt.mode)
+			if v := td.Read(); v != tt.want {
+				t.Logf("expected %d but got %d", tt.want, v)
+				t.FailNow()
+			}
+		})
+	}
+}
+
+func Test_mainsSamples(t *testing.T) {
+	tests := []struct {
+		rate, hz float64
+		want     int
+	}{
+		// 8 conversions at 80Hz are 100ms, 5 periods of 50Hz
+		{rate: 80, hz: 50, want: 8},
+		// 4 conversions at 80Hz are 50ms, 3 periods of 60Hz
+		{rate: 80, hz: 60, want: 4},
+		{rate: 10, hz: 50, want: 1},
+		{rate: 10, hz: 60, want: 1},
+		{rate: 20, hz: 50, want: 2},
+	}
+	for _, tt := range tests {
+		if got := mainsSamples(tt.rate, tt.hz); got != tt.want {
+			t.Logf("at %fHz for %fHz mains expected %d conversions but got %d", tt.rate, tt.hz, tt.want, got)
+			t.FailNow()
+		}
+	}
+}
+
+func TestDevice_ReadRejectMains(t *testing.T) {
+	dtp := &counterDataPin{}
+	// 1000 with a 60Hz hum of 200 sampled at 80Hz
+	dtp.loadBits([]uint32{1000, 800, 1000, 1200}, false)
+	td := Device{
+		sck:             dtp,
+		dt:              dtp,
+		initialized:     true,
+		gain:            Gain128,
+		smoothingFactor: 2,
+		sampleRate:      80,
+		offset:          100,
+	}
+	if v := td.ReadRejectMains(60); v != 900 {
+		t.Logf("expected the hum to cancel out to %d but got %d", 900, v)
+		t.FailNow()
+	}
+	if dtp.getIdx != 4*24 {
+		t.Logf("expected 4 conversions but %d bits were read", dtp.getIdx)
+		t.FailNow()
+	}
+}
+
+func Test_average_steps(t *testing.T) {
+	tests := []struct {
+		name   string
+		cfg    avgConfig
+		values []uint32
+		want   []avgStep
+	}{
+		{
+			name:   "running",
+			values: []uint32{1000, 1050, 1200, 1030, 1020},
+			want: []avgStep{
+				{value: 1000, running: 1000},
+				{value: 1050, running: 1025},
+				{value: 1200, rejected: true, running: 1025},
+				{value: 1030, running: 1027},
+				// the fixed threshold compares unsigned values, a drop of any size is rejected
+				{value: 1020, rejected: true, running: 1027},
+			},
+		},
+		{
+			name:   "running percent",
+			cfg:    avgConfig{outlierPercent: 1},
+			values: []uint32{1000000, 1005000, 1020000, 995000},
+			want: []avgStep{
+				{value: 1000000, running: 1000000},
+				{value: 1005000, running: 1002500},
+				{value: 1020000, rejected: true, running: 1002500},
+				{value: 995000, running: 998750},
+			},
+		},
+		{
+			name:   "mean",
+			cfg:    avgConfig{mode: SmoothingMean},
+			values: []uint32{1000, 1050, 1200, 1020},
+			want: []avgStep{
+				{value: 1000, running: 1000},
+				{value: 1050, running: 1025},
+				{value: 1200, rejected: true, running: 1025},
+				{value: 1020, running: 1023},
+			},
+		},
+		{
+			name:   "median",
+			cfg:    avgConfig{mode: SmoothingMedian},
+			values: []uint32{1000, 1050, 1200, 1020},
+			want: []avgStep{
+				{value: 1000, running: 1050},
+				{value: 1050, running: 1050},
+				{value: 1200, rejected: true, running: 1050},
+				{value: 1020, running: 1050},
+			},
+		},
+		{
+			name:   "recency",
+			cfg:    avgConfig{mode: SmoothingRecency},
+			values: []uint32{1000, 1050, 1200, 1020},
+			want: []avgStep{
+				{value: 1000, running: 1000},
+				// (1000*0.5 + 1050) / 1.5
+				{value: 1050, running: 1033},
+				{value: 1200, rejected: true, running: 1033},
+				// (1000*0.25 + 1050*0.5 + 1020) / 1.75
+				{value: 1020, running: 1026},
+			},
+		},
+		{
+			name:   "keep outliers",
+			cfg:    avgConfig{keepOutliers: true},
+			values: []uint32{1000, 1200, 900},
+			want: []avgStep{
+				{value: 1000, running: 1000},
+				{value: 1200, running: 1100},
+				{value: 900, running: 1000},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var steps []avgStep
+			cfg := tt.cfg
+			cfg.observe = func(step avgStep) { steps = append(steps, step) }
+			i := 0
+			f := func() uint32 {
+				v := tt.values[i]
+				i++
+				return v
+			}
+			average(len(tt.values), f, cfg)
+			if len(steps) != len(tt.want) {
+				t.Logf("expected %d steps but got %d", len(tt.want), len(steps))
+				t.FailNow()
+			}
+			for i, step := range steps {
+				if step != tt.want[i] {
+					t.Logf("step %d: expected %+v but got %+v", i, tt.want[i], step)
+					t.FailNow()
+				}
+			}
+		})
+	}
+}
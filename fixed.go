@@ -0,0 +1,105 @@
+package hx711
+
+import (
+	"fmt"
+	"math"
+)
+
+// ratioDenominator is the denominator used when a float calibration factor is turned into a ratio, it gives
+// 6 decimals of precision to the factor.
+const ratioDenominator = 1000000
+
+// milliUnits is the scale of the fixed point calibration, ratios are in thousandths of the calibration unit
+// so integer reads keep some precision, ie: milligrams when calibrating in grams.
+const milliUnits = 1000
+
+// ratio is a calibration factor expressed as thousandths of the calibration unit per raw unit num/den, the
+// zero value is milliUnits/1, a unit per raw unit like the factor of 1 of an uncalibrated device.
+type ratio struct {
+	num, den int64
+}
+
+// ratioFromFactor approximates a float calibration factor.
+func ratioFromFactor(factor float64) ratio {
+	return ratio{num: int64(math.Round(factor * milliUnits * ratioDenominator)), den: ratioDenominator}
+}
+
+// apply scales raw by the ratio rounding half away from zero.
+func (r ratio) apply(raw int64) int64 {
+	if r.den == 0 {
+		return raw * milliUnits
+	}
+	scaled := raw * r.num
+	half := r.den / 2
+	if (scaled < 0) != (r.den < 0) {
+		half = -half
+	}
+	return (scaled + half) / r.den
+}
+
+// setCalibrationRatio sets the calibration as a ratio without any float math, calibrationFactor is derived from it
+// when first needed, see syncFactor.
+func (d *Device) setCalibrationRatio(r ratio) {
+	d.ratio = r
+	d.factorStale = true
+	d.hasCalibration = true
+	d.calibrationTime = d.clock().Now()
+}
+
+// syncFactor derives calibrationFactor from a ratio set with setCalibrationRatio, every use of calibrationFactor
+// goes through it so float reads see the same calibration.
+func (d *Device) syncFactor() {
+	if !d.factorStale {
+		return
+	}
+	d.calibrationFactor = float64(d.ratio.num) / float64(d.ratio.den) / milliUnits
+	d.factorStale = false
+}
+
+// SetCalibrationRatio sets the calibration factor as the fraction num/den thousandths of the calibration unit
+// (ie: milligrams) per raw unit, this is for targets where float is too expensive, see ReadMilligrams.
+func (d *Device) SetCalibrationRatio(num, den int64) error {
+	d.opMutex.Lock()
+	defer d.opMutex.Unlock()
+	if den == 0 || num == 0 {
+		return fmt.Errorf("calibration ratio %d/%d: %w", num, den, ErrZeroFactor)
+	}
+	d.setCalibrationRatio(ratio{num: num, den: den})
+	return nil
+}
+
+// CalibrateFixed is Calibrate without float, it takes the known correct weight of the current load in
+// milligrams, or thousandths of whatever unit you calibrate in, and calculates the calibration ratio, see
+// ReadMilligrams.
+// Like Calibrate, offset and tare are taken into account, so zero the empty scale first.
+func (d *Device) CalibrateFixed(weightInMilligrams int64) error {
+	d.opMutex.Lock()
+	defer d.opMutex.Unlock()
+	if d.notInitialized() {
+		return ErrNotInitialized
+	}
+	if weightInMilligrams == 0 {
+		return fmt.Errorf("calibrating: %w", ErrZeroWeight)
+	}
+	raw := d.sample() - d.offset - d.tare
+	if raw == 0 {
+		return fmt.Errorf("the load reads as 0: %w", ErrZeroFactor)
+	}
+	d.setCalibrationRatio(ratio{num: weightInMilligrams, den: raw})
+	return nil
+}
+
+// ReadMilligrams performs avg of <SmoothingFactor> reads and returns the weight in milligrams, or thousandths
+// of the calibration unit, adjusted for offset, tare and calibration using only integer math.
+func (d *Device) ReadMilligrams() int64 {
+	d.opMutex.Lock()
+	defer d.opMutex.Unlock()
+	if d.notInitialized() {
+		return 0
+	}
+	mu := d.ratio.apply(d.measure() - d.offset - d.tare)
+	if abs64(mu) < d.zeroBandMilli {
+		return 0
+	}
+	return mu
+}
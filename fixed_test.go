@@ -0,0 +1,124 @@
+package hx711
+
+import (
+	"math"
+	"testing"
+)
+
+func Test_ratio_apply(t *testing.T) {
+	tests := []struct {
+		r    ratio
+		raw  int64
+		want int64
+	}{
+		{r: ratio{}, raw: 1234, want: 1234000},
+		{r: ratio{num: 1, den: 3}, raw: 10, want: 3},
+		{r: ratio{num: 1, den: 3}, raw: 11, want: 4},
+		{r: ratio{num: 1, den: 3}, raw: -11, want: -4},
+		{r: ratio{num: 1, den: -3}, raw: 11, want: -4},
+		{r: ratio{num: 5, den: 2}, raw: 3, want: 8},
+	}
+	for _, tt := range tests {
+		if got := tt.r.apply(tt.raw); got != tt.want {
+			t.Logf("%d * %d/%d expected %d but got %d", tt.raw, tt.r.num, tt.r.den, tt.want, got)
+			t.FailNow()
+		}
+	}
+}
+
+func TestDevice_ReadMilligrams(t *testing.T) {
+	for _, factor := range []float64{1, 0.123456, 2.5, 17.000001, -3.3} {
+		dtp := &counterDataPin{loop: true}
+		dtp.loadBits([]uint32{812345}, false)
+		td := Device{
+			sck:               dtp,
+			dt:                dtp,
+			initialized:       true,
+			gain:              Gain128,
+			smoothingFactor:   1,
+			calibrationFactor: 1,
+			offset:            12345,
+		}
+		td.SetCalibrationFactor(factor)
+		fixed := td.ReadMilligrams()
+		float := td.ReadGrams() * milliUnits
+		if math.Abs(float64(fixed)-float) > 1 {
+			t.Logf("with factor %f fixed point read %d but float read %f", factor, fixed, float)
+			t.FailNow()
+		}
+	}
+}
+
+func TestDevice_CalibrateFixed(t *testing.T) {
+	dtp := &counterDataPin{loop: true}
+	dtp.loadBits([]uint32{312345}, false)
+	td := Device{
+		sck:               dtp,
+		dt:                dtp,
+		initialized:       true,
+		gain:              Gain128,
+		smoothingFactor:   1,
+		calibrationFactor: 1,
+		offset:            12345,
+	}
+	if err := td.CalibrateFixed(0); err == nil {
+		t.Log("expected an error calibrating with 0mg")
+		t.FailNow()
+	}
+	if err := td.CalibrateFixed(100000); err != nil {
+		t.Fatal(err)
+	}
+	if v := td.ReadMilligrams(); v != 100000 {
+		t.Logf("expected %d milligrams but got %d", 100000, v)
+		t.FailNow()
+	}
+	// the float factor is only derived when something needs it
+	if td.calibrationFactor != 1 || !td.factorStale {
+		t.Logf("expected the float factor to be left alone by the fixed point path but is %f", td.calibrationFactor)
+		t.FailNow()
+	}
+	if f := td.GetCalibrationFactor(); math.Abs(f-100.0/300000) > 1e-12 {
+		t.Logf("expected a factor of %f but got %f", 100.0/300000, f)
+		t.FailNow()
+	}
+	if g := td.ReadGrams(); math.Abs(g-100) > 1e-9 {
+		t.Logf("expected the float path to read %f grams but got %f", 100.0, g)
+		t.FailNow()
+	}
+	if err := td.SetCalibrationRatio(1, 0); err == nil {
+		t.Log("expected an error setting a ratio with 0 denominator")
+		t.FailNow()
+	}
+	if err := td.SetCalibrationRatio(1, 3); err != nil {
+		t.Fatal(err)
+	}
+	if v := td.ReadMilligrams(); v != 100000 {
+		t.Logf("expected %d milligrams but got %d", 100000, v)
+		t.FailNow()
+	}
+}
+
+func TestDevice_ReadMilligrams_notCalibrated(t *testing.T) {
+	dtp := &counterDataPin{loop: true}
+	dtp.loadBits([]uint32{1100}, false)
+	td := Device{
+		sck:               dtp,
+		dt:                dtp,
+		initialized:       true,
+		gain:              Gain128,
+		smoothingFactor:   1,
+		calibrationFactor: 1,
+		offset:            100,
+	}
+	check := func(when string) {
+		g, mg := td.ReadGrams(), td.ReadMilligrams()
+		if mg != 1000000 || float64(mg) != g*milliUnits {
+			t.Logf("%s expected %d milli units, a thousand times %f, but got %d", when, 1000000, g, mg)
+			t.FailNow()
+		}
+	}
+	check("uncalibrated")
+	td.SetCalibrationFactor(3)
+	td.ResetCalibration()
+	check("after a reset")
+}
@@ -0,0 +1,44 @@
+package hx711
+
+// GpiodLine is the part of a gpiod line (ie: *gpiod.Line from github.com/warthog618/go-gpiocdev, formerly
+// gpiod) the adapters need, so this package does not depend on it. Request SCK as output and DT as input with
+// the right bias before using them.
+type GpiodLine interface {
+	SetValue(value int) error
+	Value() (int, error)
+}
+
+// gpiodSCK drives SCK through a gpiod line, SetValue errors are ignored as the line is already known to work
+// by the time the chip is being clocked.
+type gpiodSCK struct {
+	line GpiodLine
+}
+
+func (g gpiodSCK) High() { _ = g.line.SetValue(1) }
+func (g gpiodSCK) Low()  { _ = g.line.SetValue(0) }
+
+// gpiodDT reads DT through a gpiod line, a failed read is taken as high, which is the chip not being ready,
+// so a line that can't be read never passes for a conversion.
+type gpiodDT struct {
+	line GpiodLine
+}
+
+func (g gpiodDT) Get() bool {
+	v, err := g.line.Value()
+	return err != nil || v != 0
+}
+
+// GpiodSCK adapts a gpiod line to SCK.
+func GpiodSCK(line GpiodLine) SCK {
+	return gpiodSCK{line: line}
+}
+
+// GpiodDT adapts a gpiod line to DT.
+func GpiodDT(line GpiodLine) DT {
+	return gpiodDT{line: line}
+}
+
+// NewFromGpiod is NewWithOptions for gpiod lines, ie: on single board computers running a modern Linux.
+func NewFromGpiod(sck, dt GpiodLine, opts ...Option) *Device {
+	return NewWithOptions(GpiodSCK(sck), GpiodDT(dt), opts...)
+}
@@ -0,0 +1,47 @@
+package hx711
+
+import (
+	"errors"
+	"testing"
+)
+
+// fakeGpiodLine records the values set and returns the loaded ones, like a gpiod line.
+type fakeGpiodLine struct {
+	set    []int
+	values []int
+	errs   []error
+	idx    int
+}
+
+func (f *fakeGpiodLine) SetValue(value int) error {
+	f.set = append(f.set, value)
+	return nil
+}
+
+func (f *fakeGpiodLine) Value() (int, error) {
+	v, err := f.values[f.idx], f.errs[f.idx]
+	f.idx++
+	return v, err
+}
+
+func TestGpiodAdapters(t *testing.T) {
+	sckLine := &fakeGpiodLine{}
+	sck := GpiodSCK(sckLine)
+	sck.High()
+	sck.Low()
+	if len(sckLine.set) != 2 || sckLine.set[0] != 1 || sckLine.set[1] != 0 {
+		t.Logf("expected High and Low to set 1 and 0 but got %v", sckLine.set)
+		t.FailNow()
+	}
+	dtLine := &fakeGpiodLine{
+		values: []int{1, 0, 0},
+		errs:   []error{nil, nil, errors.New("line released")},
+	}
+	dt := GpiodDT(dtLine)
+	for i, want := range []bool{true, false, true} {
+		if got := dt.Get(); got != want {
+			t.Logf("expected read %d to be %v but got %v", i, want, got)
+			t.FailNow()
+		}
+	}
+}
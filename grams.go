@@ -0,0 +1,238 @@
+package hx711
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"time"
+)
+
+// Calibration does not care about units, the value is whatever unit the known weights were given in when
+// calibrating (grams, ounces, newtons...) and calibrated reads are in that same unit. The Grams methods are
+// named after the most common case but they also return the calibration unit.
+
+// toGrams converts a raw value, already adjusted for offset and tare, into the calibration unit.
+func (d *Device) toGrams(raw int64) float64 {
+	return float64(raw) * d.factor()
+}
+
+// weight converts a raw value, already adjusted for offset and tare, into the calibration unit applying the
+// zero band, this is what reads return.
+func (d *Device) weight(raw int64) float64 {
+	w := d.toGrams(raw)
+	if math.Abs(w) < d.zeroBand {
+		return 0
+	}
+	return w
+}
+
+// SetZeroBand makes calibrated reads (ReadGrams, ReadCalibrated, ReadMilligrams and the like) with an absolute
+// value below band, in the calibration unit, return exactly 0, so an empty scale doesn't show noise. Weights
+// of band or more are not affected. 0, the default, disables it.
+func (d *Device) SetZeroBand(band float64) {
+	d.opMutex.Lock()
+	defer d.opMutex.Unlock()
+	d.zeroBand = math.Abs(band)
+	d.zeroBandMilli = int64(math.Round(d.zeroBand * milliUnits))
+}
+
+// fromGrams converts a value in the calibration unit into raw units.
+func (d *Device) fromGrams(grams float64) int64 {
+	return int64(math.Round(grams / d.factor()))
+}
+
+// GetTareGrams returns the current tare in grams, it requires the device to be calibrated.
+func (d *Device) GetTareGrams() (float64, error) {
+	d.opMutex.Lock()
+	defer d.opMutex.Unlock()
+	if !d.hasCalibration {
+		return 0, fmt.Errorf("tare in grams: %w", ErrNotCalibrated)
+	}
+	return d.toGrams(d.tare), nil
+}
+
+// SetTareGrams sets the tare to a known weight in grams, ie: the weight of a container, it requires the device
+// to be calibrated.
+func (d *Device) SetTareGrams(grams float64) error {
+	d.opMutex.Lock()
+	defer d.opMutex.Unlock()
+	if !d.hasCalibration {
+		return fmt.Errorf("tare in grams: %w", ErrNotCalibrated)
+	}
+	d.tare = d.fromGrams(grams)
+	return nil
+}
+
+// ReadGrams performs avg of <SmoothingFactor> reads and returns the weight in grams, adjusted for offset,
+// tare and calibration, unlike ReadCalibrated no accuracy is lost.
+func (d *Device) ReadGrams() float64 {
+	d.opMutex.Lock()
+	defer d.opMutex.Unlock()
+	if d.notInitialized() {
+		return 0
+	}
+	return d.weight(d.measure() - d.offset - d.tare)
+}
+
+// Resolution returns the weight, in the calibration unit, of one count of the chip on the selected channel,
+// the smallest change the setup can tell apart, noise aside. The factor already accounts for the gain it was
+// calibrated at. Uncalibrated devices return 1, as reads are raw counts.
+func (d *Device) Resolution() float64 {
+	d.opMutex.Lock()
+	defer d.opMutex.Unlock()
+	return math.Abs(d.factor())
+}
+
+// SetUnit sets the name of the calibration unit, ie: "g", "kg" or "lb", for FormatReading. Calibration is
+// unit agnostic, this is only a label.
+func (d *Device) SetUnit(unit string) {
+	d.opMutex.Lock()
+	defer d.opMutex.Unlock()
+	d.unit = unit
+}
+
+// maxDecimals is the most decimals FormatReading shows, past it the resolution is mostly noise anyway.
+const maxDecimals = 6
+
+// FormatReading renders value, as returned by Read, in the calibration unit followed by the unit set with
+// SetUnit, ie: "123.4 g". It shows as many decimals as the resolution of the device calls for, so the last
+// digit is about one count. The zero band applies.
+func (d *Device) FormatReading(value int64) string {
+	d.opMutex.Lock()
+	defer d.opMutex.Unlock()
+	decimals := 0
+	if r := math.Abs(d.factor()); r > 0 && r < 1 {
+		decimals = int(math.Ceil(-math.Log10(r)))
+		if decimals > maxDecimals {
+			decimals = maxDecimals
+		}
+	}
+	s := strconv.FormatFloat(d.weight(value), 'f', decimals, 64)
+	if d.unit == "" {
+		return s
+	}
+	return s + " " + d.unit
+}
+
+// StandardGravity is the standard acceleration of gravity in m/s², what ReadForce uses when passed 0.
+const StandardGravity = 9.80665
+
+// ReadForce reads like ReadGrams and returns the force the load exerts, weight times gravity, in m/s² or
+// StandardGravity if 0 is passed. Calibrating in kilograms gives newtons, in grams millinewtons.
+func (d *Device) ReadForce(gravity float64) float64 {
+	if gravity == 0 {
+		gravity = StandardGravity
+	}
+	return d.ReadGrams() * gravity
+}
+
+// ReadN performs n successive reads and returns them in grams adjusted for offset, tare and calibration, each
+// is a full read of <SmoothingFactor> reads unless single is true, in which case each is a single conversion.
+func (d *Device) ReadN(n int, single bool) []float64 {
+	d.opMutex.Lock()
+	defer d.opMutex.Unlock()
+	if d.notInitialized() {
+		return nil
+	}
+	if n < 0 {
+		n = 0
+	}
+	values := make([]float64, n)
+	for i := range values {
+		var raw int64
+		if single {
+			raw = d.sampleN(1)
+		} else {
+			raw = d.measure()
+		}
+		values[i] = d.weight(raw - d.offset - d.tare)
+	}
+	return values
+}
+
+// IsStable performs <window> reads and reports whether they are all within tolerance (raw units) of each other.
+func (d *Device) IsStable(tolerance int64, window int) bool {
+	d.opMutex.Lock()
+	defer d.opMutex.Unlock()
+	if d.notInitialized() {
+		return false
+	}
+	values := make([]int64, 0, window)
+	for i := 0; i < window; i++ {
+		values = append(values, d.measure())
+	}
+	return spread(values) <= tolerance
+}
+
+// WeighStable reads until <window> consecutive reads are within tolerance (raw units) of each other and
+// returns their average in grams, like a point of sale scale would, or an error if that takes longer than timeout.
+func (d *Device) WeighStable(tolerance int64, window int, timeout time.Duration) (float64, error) {
+	if window < 1 {
+		window = 1
+	}
+	d.opMutex.Lock()
+	if d.notInitialized() {
+		d.opMutex.Unlock()
+		return 0, ErrNotInitialized
+	}
+	d.opMutex.Unlock()
+	deadline := d.clock().Now().Add(timeout)
+	values := make([]int64, 0, window)
+	for {
+		d.opMutex.Lock()
+		v := d.measure() - d.offset - d.tare
+		d.opMutex.Unlock()
+		if len(values) == window {
+			values = append(values[:0], values[1:]...)
+		}
+		values = append(values, v)
+		if len(values) == window && spread(values) <= tolerance {
+			var sum int64
+			for _, v := range values {
+				sum += v
+			}
+			d.opMutex.Lock()
+			defer d.opMutex.Unlock()
+			if w := d.toGrams(sum) / float64(window); math.Abs(w) >= d.zeroBand {
+				return w, nil
+			}
+			return 0, nil
+		}
+		if d.clock().Now().After(deadline) {
+			return 0, fmt.Errorf("weight did not stabilize in %s: %w", timeout, ErrTimeout)
+		}
+	}
+}
+
+// spread returns the difference between the largest and the smallest of values.
+func spread(values []int64) int64 {
+	if len(values) == 0 {
+		return 0
+	}
+	min, max := values[0], values[0]
+	for _, v := range values[1:] {
+		if v < min {
+			min = v
+		}
+		if v > max {
+			max = v
+		}
+	}
+	return max - min
+}
+
+// VerifyCalibration reads the current load, which should weigh knownGrams, and returns the error of the
+// reading as a percentage of knownGrams and whether it is within tolerancePct.
+func (d *Device) VerifyCalibration(knownGrams float64, tolerancePct float64) (errorPct float64, ok bool) {
+	d.opMutex.Lock()
+	defer d.opMutex.Unlock()
+	if d.notInitialized() {
+		return math.Inf(1), false
+	}
+	if knownGrams == 0 {
+		return math.Inf(1), false
+	}
+	measured := d.toGrams(d.measure() - d.offset - d.tare)
+	errorPct = (measured - knownGrams) / knownGrams * 100
+	return errorPct, math.Abs(errorPct) <= tolerancePct
+}
@@ -0,0 +1,342 @@
+package hx711
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+func TestDevice_ReadGrams(t *testing.T) {
+	dtp := &counterDataPin{}
+	dtp.loadBits([]uint32{1500}, false)
+	td := Device{
+		sck:               dtp,
+		dt:                dtp,
+		initialized:       true,
+		gain:              Gain128,
+		smoothingFactor:   1,
+		calibrationFactor: 0.01,
+		offset:            500,
+	}
+	// 1000 counts at 0.01g per count
+	if g := td.ReadGrams(); g != 10 {
+		t.Logf("expected %f grams but got %f", 10.0, g)
+		t.FailNow()
+	}
+}
+
+func TestDevice_IsStable(t *testing.T) {
+	dtp := &counterDataPin{}
+	dtp.loadBits([]uint32{1000, 1300, 900, 1000, 1001, 1000}, false)
+	td := Device{
+		sck:             dtp,
+		dt:              dtp,
+		initialized:     true,
+		gain:            Gain128,
+		smoothingFactor: 1,
+	}
+	if td.IsStable(2, 3) {
+		t.Log("expected noisy reads to not be stable")
+		t.FailNow()
+	}
+	if !td.IsStable(2, 3) {
+		t.Log("expected quiet reads to be stable")
+		t.FailNow()
+	}
+}
+
+func TestDevice_Resolution(t *testing.T) {
+	td := NewPreset(CalibrationState{})
+	if r := td.Resolution(); r != 1 {
+		t.Logf("expected uncalibrated resolution to be 1 count but got %f", r)
+		t.FailNow()
+	}
+	// a 1kg cell at 2mV/V gives 1000g in 4294967.296 counts at Gain128
+	if _, err := td.ConfigureFromRating(1000, 2, 5, Gain128); err != nil {
+		t.Fatal(err)
+	}
+	if r := td.Resolution(); math.Abs(r-1000/4294967.296) > 1e-15 {
+		t.Logf("expected %g grams per count but got %g", 1000/4294967.296, r)
+		t.FailNow()
+	}
+	td.SetCalibrationFactor(-0.01)
+	if r := td.Resolution(); r != 0.01 {
+		t.Logf("expected %g grams per count for an inverted cell but got %g", 0.01, r)
+		t.FailNow()
+	}
+}
+
+func TestDevice_SetZeroBand(t *testing.T) {
+	tests := []struct {
+		name string
+		raw  uint32
+		want float64
+	}{
+		{name: "empty", raw: 1000, want: 0},
+		{name: "within", raw: 1049, want: 0},
+		{name: "within negative", raw: 951, want: 0},
+		{name: "at the band", raw: 1050, want: 0.5},
+		{name: "just above", raw: 1051, want: 0.51},
+		{name: "just above negative", raw: 949, want: -0.51},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dtp := &counterDataPin{loop: true}
+			dtp.loadBits([]uint32{tt.raw}, false)
+			td := Device{
+				sck:             dtp,
+				dt:              dtp,
+				initialized:     true,
+				gain:            Gain128,
+				smoothingFactor: 1,
+				offset:          1000,
+			}
+			td.SetCalibrationFactor(0.01)
+			td.SetZeroBand(0.5)
+			if g := td.ReadGrams(); math.Abs(g-tt.want) > 1e-9 {
+				t.Logf("expected %f grams but got %f", tt.want, g)
+				t.FailNow()
+			}
+			if mg := td.ReadMilligrams(); mg != int64(math.Round(tt.want*1000)) {
+				t.Logf("expected %d milligrams but got %d", int64(math.Round(tt.want*1000)), mg)
+				t.FailNow()
+			}
+		})
+	}
+}
+
+func TestDevice_ReadForce(t *testing.T) {
+	tests := []struct {
+		name    string
+		gravity float64
+		want    float64
+	}{
+		{name: "standard", gravity: 0, want: 2 * StandardGravity},
+		{name: "moon", gravity: 1.62, want: 3.24},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dtp := &counterDataPin{}
+			dtp.loadBits([]uint32{2500}, false)
+			// calibrated in kilograms
+			td := Device{
+				sck:               dtp,
+				dt:                dtp,
+				initialized:       true,
+				gain:              Gain128,
+				smoothingFactor:   1,
+				calibrationFactor: 0.001,
+				offset:            500,
+			}
+			if f := td.ReadForce(tt.gravity); math.Abs(f-tt.want) > 1e-9 {
+				t.Logf("expected %fN but got %f", tt.want, f)
+				t.FailNow()
+			}
+		})
+	}
+}
+
+func TestDevice_WeighStable(t *testing.T) {
+	dtp := &counterDataPin{}
+	dtp.loadBits([]uint32{1000, 1300, 900, 2000, 2001, 2002, 2000}, false)
+	td := Device{
+		sck:               dtp,
+		dt:                dtp,
+		initialized:       true,
+		gain:              Gain128,
+		smoothingFactor:   1,
+		calibrationFactor: 1,
+	}
+	g, err := td.WeighStable(2, 3, time.Second)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if g != 2001 {
+		t.Logf("expected %f grams but got %f", 2001.0, g)
+		t.FailNow()
+	}
+	if dtp.getIdx != 6*24 {
+		t.Logf("expected to stop reading once stable, but read %d bits", dtp.getIdx)
+		t.FailNow()
+	}
+}
+
+func TestDevice_WeighStable_timeout(t *testing.T) {
+	dtp := &counterDataPin{loop: true}
+	dtp.loadBits([]uint32{1000, 1300}, false)
+	td := Device{
+		sck:               dtp,
+		dt:                dtp,
+		initialized:       true,
+		gain:              Gain128,
+		smoothingFactor:   1,
+		calibrationFactor: 1,
+	}
+	if _, err := td.WeighStable(2, 3, 5*time.Millisecond); err == nil {
+		t.Log("expected an error for a weight that never stabilizes")
+		t.FailNow()
+	}
+}
+
+func TestDevice_TareGrams(t *testing.T) {
+	td := NewPreset(CalibrationState{})
+	if _, err := td.GetTareGrams(); err == nil {
+		t.Log("expected an error getting tare in grams without calibration")
+		t.FailNow()
+	}
+	if err := td.SetTareGrams(10); err == nil {
+		t.Log("expected an error setting tare in grams without calibration")
+		t.FailNow()
+	}
+
+	td.SetCalibrationFactor(0.0025)
+	if err := td.SetTareGrams(125.5); err != nil {
+		t.Fatal(err)
+	}
+	// 125.5g at 0.0025g per count
+	if td.tare != 50200 {
+		t.Logf("expected tare to be %d but is %d", 50200, td.tare)
+		t.FailNow()
+	}
+	g, err := td.GetTareGrams()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if g != 125.5 {
+		t.Logf("expected tare to be %f grams but is %f", 125.5, g)
+		t.FailNow()
+	}
+}
+
+func TestDevice_VerifyCalibration(t *testing.T) {
+	tests := []struct {
+		name     string
+		factor   float64
+		errorPct float64
+		ok       bool
+	}{
+		{name: "calibrated", factor: 0.01, errorPct: 0, ok: true},
+		{name: "slightly off", factor: 0.01005, errorPct: 0.5, ok: true},
+		{name: "miscalibrated", factor: 0.011, errorPct: 10, ok: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dtp := &counterDataPin{}
+			// 100g at 0.01g per count
+			dtp.loadBits([]uint32{10000}, false)
+			td := Device{
+				sck:               dtp,
+				dt:                dtp,
+				initialized:       true,
+				gain:              Gain128,
+				smoothingFactor:   1,
+				calibrationFactor: tt.factor,
+			}
+			errorPct, ok := td.VerifyCalibration(100, 1)
+			if math.Abs(errorPct-tt.errorPct) > 1e-9 || ok != tt.ok {
+				t.Logf("expected error of %f%% (ok %v) but got %f%% (ok %v)", tt.errorPct, tt.ok, errorPct, ok)
+				t.FailNow()
+			}
+		})
+	}
+}
+
+func TestDevice_ReadN(t *testing.T) {
+	for _, single := range []bool{false, true} {
+		dtp := &counterDataPin{}
+		values := []uint32{1100, 1200, 1300}
+		if !single {
+			values = []uint32{1100, 1100, 1200, 1200, 1300, 1300}
+		}
+		dtp.loadBits(values, false)
+		td := Device{
+			sck:               dtp,
+			dt:                dtp,
+			initialized:       true,
+			gain:              Gain128,
+			smoothingFactor:   2,
+			calibrationFactor: 0.01,
+			offset:            60,
+			tare:              40,
+		}
+		got := td.ReadN(3, single)
+		want := []float64{10, 11, 12}
+		if len(got) != len(want) {
+			t.Logf("single %v expected %d values but got %d", single, len(want), len(got))
+			t.FailNow()
+		}
+		for i := range want {
+			if math.Abs(got[i]-want[i]) > 1e-9 {
+				t.Logf("single %v expected %v but got %v", single, want, got)
+				t.FailNow()
+			}
+		}
+		if dtp.getIdx != len(dtp.get) {
+			t.Logf("single %v expected %d bits to be read but %d were", single, len(dtp.get), dtp.getIdx)
+			t.FailNow()
+		}
+	}
+}
+
+func TestDevice_calibrationUnits(t *testing.T) {
+	tests := []struct {
+		name  string
+		known float64
+	}{
+		{name: "newtons", known: 9.81},
+		{name: "ounces", known: 35.274},
+		{name: "grams", known: 1000},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dtp := &counterDataPin{loop: true}
+			dtp.loadBits([]uint32{200000}, false)
+			td := Device{
+				sck:               dtp,
+				dt:                dtp,
+				initialized:       true,
+				gain:              Gain128,
+				smoothingFactor:   1,
+				calibrationFactor: 1,
+			}
+			if _, err := td.Calibrate(tt.known); err != nil {
+				t.Fatal(err)
+			}
+			if v := td.ReadGrams(); math.Abs(v-tt.known) > 1e-9 {
+				t.Logf("expected to read %f in the calibration unit but got %f", tt.known, v)
+				t.FailNow()
+			}
+			if v := td.ReadMilligrams(); v != int64(math.Round(tt.known*1000)) {
+				t.Logf("expected to read %d thousandths of the calibration unit but got %d", int64(math.Round(tt.known*1000)), v)
+				t.FailNow()
+			}
+		})
+	}
+}
+
+func TestDevice_FormatReading(t *testing.T) {
+	tests := []struct {
+		name   string
+		factor float64
+		unit   string
+		value  int64
+		want   string
+	}{
+		{name: "grams", factor: 0.1, unit: "g", value: 1234, want: "123.4 g"},
+		{name: "kilograms", factor: 0.0005, unit: "kg", value: 2469, want: "1.2345 kg"},
+		{name: "negative", factor: 0.1, unit: "g", value: -1234, want: "-123.4 g"},
+		{name: "coarse", factor: 2, unit: "lb", value: 21, want: "42 lb"},
+		{name: "no unit", factor: 0.25, value: 6, want: "1.5"},
+		{name: "uncalibrated", value: 1234, want: "1234"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			td := NewPreset(CalibrationState{CalibrationFactor: tt.factor})
+			td.SetUnit(tt.unit)
+			if got := td.FormatReading(tt.value); got != tt.want {
+				t.Logf("expected %q but got %q", tt.want, got)
+				t.FailNow()
+			}
+		})
+	}
+}
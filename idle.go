@@ -0,0 +1,32 @@
+package hx711
+
+import "fmt"
+
+// idleChecks is how many times StartupCheck samples DT.
+const idleChecks = 5
+
+// SetExpectedIdleState sets the level DT is expected to idle at before any conversion, which depends on how the
+// pin pull was configured (high for machine.PinInputPullup), see StartupCheck.
+func (d *Device) SetExpectedIdleState(high bool) {
+	d.opMutex.Lock()
+	defer d.opMutex.Unlock()
+	d.expectedIdle = high
+	d.hasExpectedIdle = true
+}
+
+// StartupCheck samples DT a few times without clocking anything and returns an error wrapping ErrWrongIdleState
+// if it never was at the level set with SetExpectedIdleState, which usually means the pin pull is configured the
+// wrong way (see the note on DT about Espressif boards). It does nothing if no expected state was set.
+func (d *Device) StartupCheck() error {
+	d.opMutex.Lock()
+	defer d.opMutex.Unlock()
+	if !d.hasExpectedIdle {
+		return nil
+	}
+	for i := 0; i < idleChecks; i++ {
+		if d.dt.Get() == d.expectedIdle {
+			return nil
+		}
+	}
+	return fmt.Errorf("DT read %v %d times, expected %v: %w", !d.expectedIdle, idleChecks, d.expectedIdle, ErrWrongIdleState)
+}
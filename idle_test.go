@@ -0,0 +1,39 @@
+package hx711
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestDevice_StartupCheck(t *testing.T) {
+	tests := []struct {
+		name string
+		dt   []bool
+		high bool
+		want error
+	}{
+		{name: "pulled up", dt: []bool{true}, high: true},
+		{name: "pulled down", dt: []bool{false}, high: false},
+		{name: "settles after a glitch", dt: []bool{false, false, true}, high: true},
+		{name: "mismatched", dt: []bool{false}, high: true, want: ErrWrongIdleState},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dtp := &counterDataPin{loop: true, get: tt.dt}
+			td := Device{initialized: true, sck: dtp, dt: dtp, gain: Gain128}
+			if err := td.StartupCheck(); err != nil {
+				t.Logf("expected no check without an expected state but got %v", err)
+				t.FailNow()
+			}
+			td.SetExpectedIdleState(tt.high)
+			if err := td.StartupCheck(); !errors.Is(err, tt.want) {
+				t.Logf("expected %v but got %v", tt.want, err)
+				t.FailNow()
+			}
+			if dtp.countH != 0 {
+				t.Logf("expected no clock pulses but got %d", dtp.countH)
+				t.FailNow()
+			}
+		})
+	}
+}
@@ -1,7 +1,10 @@
 package hx711
 
 import (
+	"context"
 	"fmt"
+	"io"
+	"sort"
 	"sync"
 	"time"
 )
@@ -45,8 +48,114 @@ type Device struct {
 	smoothingFactor int
 	// calibrationFactor will be used to adjust measures based on known samples
 	calibrationFactor float64
+	// warmup refines offset after a cold start, see WithWarmup
+	warmup warmup
+	// zeroBand is the calibrated value under which reads are 0, see SetZeroBand
+	zeroBand float64
+	// zeroBandMilli is zeroBand in thousandths of the calibration unit, for float free reads, see ReadMilligrams
+	zeroBandMilli int64
+	// unit is the name of the calibration unit, see SetUnit
+	unit string
+	// channelBFactor is the calibration factor of channel B, 0 to use calibrationFactor
+	channelBFactor float64
+	// ratio is calibrationFactor as a fraction, for float free reads, see ReadMilligrams
+	ratio ratio
+	// factorStale is set when ratio was set without float and calibrationFactor is yet to be derived from it
+	factorStale bool
+	// capacity is the rated capacity of the cell in grams, 0 if unknown
+	capacity float64
+	// calibrationPoints and calibrationFit are the multi point calibration, see FitCalibration
+	calibrationPoints []Point
+	calibrationFit    *CalibrationFit
+	// hasCalibration is true once a calibration factor was set or computed
+	hasCalibration bool
+	// calibrationTime is when the calibration factor was last set or computed
+	calibrationTime time.Time
+	// settlingWait is how long to wait for the chip to settle before the first read
+	settlingWait time.Duration
+	// skipBaseline makes initialization leave offset alone, see WithSkipBaseline
+	skipBaseline bool
+	// baselineBursts and baselineTolerance make the baseline discard glitched reads, see WithBaselineBursts
+	baselineBursts    int
+	baselineTolerance int64
+	// expectedIdle is the level DT should idle at, see SetExpectedIdleState
+	expectedIdle    bool
+	hasExpectedIdle bool
+	// recorder gets every conversion, see RecordReads
+	recorder io.Writer
+	// readyFunc replaces the default DT low check to know if the chip is ready, see WithReadyFunc
+	readyFunc func(DT) bool
+	// discardAfterStateChange is the amount of reads discarded after any gain or power change, at least 1
+	discardAfterStateChange int
+	// wakePulses is the amount of dummy clock pulses sent on power up and initialization, see SetWakePulses
+	wakePulses int
+	// pendingDiscard is the amount of reads to discard before the next one
+	pendingDiscard int
+	// sampler, if set, replaces the GPIO bit banging as source of conversions, see SetSampler
+	sampler Sampler
+	// inverted negates conversions, see SetInverted
+	inverted bool
+	// lastConversion is the last successful conversion, used in place of failed ones
+	lastConversion uint32
+	// minSamples, if > 0, leaves failed conversions out of the average as long as that many succeed, see
+	// SetMinSamples
+	minSamples int
+	// exclusiveClock makes reads hold clockMutex, see SetExclusiveClock
+	exclusiveClock bool
+	// captureBits keeps the DT samples of the last conversion in lastBits, see SetCaptureBits
+	captureBits bool
+	lastBits    []bool
+	// initialized is set once the chip went through initialize, see Read
+	initialized bool
+	// seenReady is set once DT was seen low, to tell a missing chip from a slow one.
+	seenReady bool
+	// tickDelay is how long to hold each clock level, see SetTickDelay
+	tickDelay time.Duration
+	// maxPulseWidth is the longest SCK may be held high before a read is dropped, see SetMaxPulseWidth
+	maxPulseWidth  time.Duration
+	clockStretched bool
+	// sampleRate is the conversion rate of the chip in Hz, see SetSampleRate
+	sampleRate float64
+	// smoothing tunes how the reads of a burst are averaged
+	smoothing avgConfig
+	// stability tracks whether reads settled, see SetStabilityHysteresis
+	stability stability
+	// outliers tracks the conversions rejected as outliers recently, see SetOutlierWindow
+	outliers outlierWindow
+	// lowPass holds the optional IIR filter applied to reads, see SetLowPass
+	lowPass lowPass
 	// we want to lock on consecutive read operations to avoid contention
 	opMutex sync.Mutex
+	// tracer, if set, is called for every step of the bit-bang sequence, see SetTracer
+	tracer func(event TraceEvent)
+	// saturated is true when a conversion of the last read was at the limits of the ADC range
+	saturated bool
+	// lastSample is the value, before offset and tare, of the most recent Read, valid if hasLastSample
+	lastSample    int64
+	hasLastSample bool
+	// lastReadTime is when the most recent Read completed
+	lastReadTime time.Time
+	// minReadInterval makes reads within it return lastSample, see SetMinReadInterval
+	minReadInterval time.Duration
+	// lastReported is the last value returned by ReadOnChange, if hasReported
+	lastReported int64
+	hasReported  bool
+	// readTimeout, if > 0, makes reads wait for the chip giving up after it, see SetReadTimeout
+	readTimeout time.Duration
+	// clk is the source of time, defaults to the time package, see clock
+	clk clock
+	// driftThreshold and onDrift are the optional drift alarm, see SetDriftThreshold
+	driftThreshold int64
+	onDrift        func(drift int64)
+	// maxDrift and maxCalibrationAge decide when recalibration is recommended, see SetMaintenanceThresholds
+	maxDrift          int64
+	maxCalibrationAge time.Duration
+	// statusBits is the number of extra bits some clones send after the data, see SetStatusBits
+	statusBits  int
+	statusCheck func(data, status uint32) bool
+	lastStatus  uint32
+	// diag holds counters about the last operations, see Diagnostics
+	diag Diagnostics
 }
 
 func toInt64(u uint32) int64 {
@@ -54,59 +163,172 @@ func toInt64(u uint32) int64 {
 }
 
 func avg(times int, f func() uint32) uint32 {
-	var r uint32
-	for i := 0; i < times; i++ {
-		rr := f()
-		pr := r
-		r += rr
-		if i == 0 {
-			continue
-		}
-		// this is a burst of N reads, if the two consecutive reads are too dissimilar we discard it as an outlier
-		// which at least in my chip happens a lot.
-		if (rr - pr) > 100 {
-			r = pr
-			continue
-		}
-		r = r / 2
-	}
-	return r
+	return average(times, f, avgConfig{})
 }
 
 // New returns a device configured and initialized with the passed ports
-// if the device is not appropriately connected this might hang
+// if the device is not appropriately connected this might hang.
+// settlingWait is in milliseconds, prefer NewWithOptions and WithSettlingWait which take a time.Duration.
 func New(sck SCK, dt DT, gain gainLVL, smoothingFactor int, settlingWait int) *Device {
-	d := &Device{sck: sck, dt: dt, smoothingFactor: smoothingFactor, calibrationFactor: 1}
-	d.SetGainAndChannel(gain)
-	if settlingWait > 0 {
-		time.Sleep(time.Duration(settlingWait) * time.Millisecond)
+	return NewWithOptions(sck, dt,
+		WithGain(gain),
+		WithSmoothingFactor(smoothingFactor),
+		WithSettlingWait(time.Duration(settlingWait)*time.Millisecond))
+}
+
+// Initialize runs the initialization New does on an existing device, ie: after a long idle: it waits for the
+// chip to settle, applies gain and channel, waits for the chip to be ready and takes the baseline offset
+// (unless WithSkipBaseline was passed). Tare and calibration are kept. Like New, this might hang if the chip is
+// not connected, unless a timeout was set with SetReadTimeout, then if the chip is not ready in time it gives up
+// returning an error matching ErrNoSensor or ErrTimeout and the device is left uninitialized. If the baseline read
+// was saturated the offset is still taken but ErrSaturated is returned.
+func (d *Device) Initialize() error {
+	d.opMutex.Lock()
+	defer d.opMutex.Unlock()
+	if err := d.initialize(); err != nil {
+		return fmt.Errorf("waiting for the chip: %w", err)
+	}
+	if d.saturated && !d.skipBaseline {
+		return fmt.Errorf("baseline: %w", ErrSaturated)
 	}
+	return nil
+}
+
+// initialize waits for the chip to settle and be ready and takes the baseline offset, if the chip is not ready
+// within the read timeout the error is recorded in Diagnostics and returned.
+func (d *Device) initialize() error {
+	if d.settlingWait > 0 {
+		d.clock().Sleep(d.settlingWait)
+	}
+	d.wake()
 	// subsequent setting of gain happens in the read
 	d.setGainAndChannel()
-	for {
-		if !d.dt.Get() {
-			break
-		}
+	ctx, cancel := d.timeoutContext()
+	defer cancel()
+	if err := d.waitReady(ctx); err != nil {
+		d.diag.LastError = d.contextError(err)
+		return d.diag.LastError
+	}
+	d.stateChanged()
+	d.initialized = true
+	if d.skipBaseline {
+		return nil
 	}
 	// make a first read to get a baseline
-	d.offset = toInt64(avg(smoothingFactor, d.read))
-	return d
+	d.offset = d.baseline()
+	d.warmup.add(d, d.offset)
+	return nil
+}
+
+// baseline reads the offset, if configured to take several bursts the ones further than baselineTolerance from
+// their median are discarded and the rest averaged, so a glitch while powering up does not end up in offset.
+func (d *Device) baseline() int64 {
+	if d.baselineBursts <= 1 {
+		return d.sample()
+	}
+	bursts := make([]int64, d.baselineBursts)
+	for i := range bursts {
+		bursts[i] = d.sample()
+	}
+	sorted := append([]int64(nil), bursts...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+	median := sorted[len(sorted)/2]
+	var sum, count int64
+	for _, b := range bursts {
+		if abs64(b-median) > d.baselineTolerance {
+			continue
+		}
+		sum += b
+		count++
+	}
+	// count can't be 0, the median itself is always kept
+	return sum / count
+}
+
+// isReady reports whether the chip has a conversion ready, which it signals by pulling DT low.
+func (d *Device) isReady() bool {
+	if d.readyFunc != nil {
+		ready := d.readyFunc(d.dt)
+		d.seenReady = d.seenReady || ready
+		return ready
+	}
+	ready := !d.dt.Get()
+	d.seenReady = d.seenReady || ready
+	return ready
 }
 
 // tick "ticks" the clock.
 // the sleep is for cases where the processor is too fast.
 func (d *Device) tick() {
+	var start time.Time
+	if d.maxPulseWidth > 0 {
+		start = d.clock().Now()
+	}
 	d.sck.High()
-	time.Sleep(time.Microsecond)
+	if d.tickDelay > 0 {
+		d.clock().Sleep(d.tickDelay)
+	}
+	if d.maxPulseWidth > 0 && d.clock().Now().Sub(start) > d.maxPulseWidth {
+		d.clockStretched = true
+	}
 	d.sck.Low()
-	time.Sleep(time.Microsecond)
+	if d.tickDelay > 0 {
+		d.clock().Sleep(d.tickDelay)
+	}
+	if d.tracer != nil {
+		d.tracer(TraceEvent{Kind: TraceTick})
+	}
+}
+
+// SetMaxPulseWidth makes reads check how long SCK was held high on each pulse, if any pulse took longer than
+// max, ie: because an interrupt got in the middle of it, the conversion is dropped as failed with an error
+// wrapping ErrClockStretched (see Diagnostics and ReadContext), the chip may have glitched or, past 60µs,
+// powered down. This costs two clock reads per pulse, 0 disables it.
+func (d *Device) SetMaxPulseWidth(max time.Duration) {
+	d.opMutex.Lock()
+	defer d.opMutex.Unlock()
+	d.maxPulseWidth = max
+}
+
+// SetTickDelay sets how long each level of a clock pulse is held, defaults to DefaultTickDelay.
+// The chip needs at least 0.2µs but holding SCK high for more than 60µs powers it down, so keep it short,
+// 0 disables the wait altogether which is only useful for tests and benchmarks or very slow processors.
+func (d *Device) SetTickDelay(delay time.Duration) {
+	d.opMutex.Lock()
+	defer d.opMutex.Unlock()
+	if delay < 0 {
+		delay = 0
+	}
+	d.tickDelay = delay
 }
 
+// EstimatedReadDuration returns how long clocking out the next Read takes: <SmoothingFactor> conversions, plus
+// the ones discarded after a change of gain, channel or power state, each of 24 bits, status bits and gain
+// pulses with both levels held for the tick delay. Waiting for the chip to finish a conversion (100ms at 10SPS)
+// is not included, the loop only needs to leave room for the read when IsReady, see also SetSampler which
+// takes the pins out of the picture.
+func (d *Device) EstimatedReadDuration() time.Duration {
+	d.opMutex.Lock()
+	defer d.opMutex.Unlock()
+	conversions := d.smoothingFactor + d.pendingDiscard
+	ticks := conversions * (24 + d.statusBits + int(d.gain))
+	return time.Duration(ticks) * 2 * d.tickDelay
+}
+
+// SetGainAndChannel selects gain and channel for the following reads, the first read after the change is
+// discarded as the chip only learns about the new selection at the end of a read. Setting the gain the device
+// already has changes nothing, so no read is discarded.
 func (d *Device) SetGainAndChannel(g gainLVL) {
+	d.opMutex.Lock()
+	defer d.opMutex.Unlock()
 	if g < Gain128 || g > Gain32 {
 		g = Gain128
 	}
+	if g == d.gain {
+		return
+	}
 	d.gain = g
+	d.stateChanged()
 }
 
 // setGainAndChannel sets channel and gain when called between reads,I believe it should be called before each read
@@ -114,27 +336,203 @@ func (d *Device) setGainAndChannel() {
 	for i := 0; i < int(d.gain); i++ {
 		d.tick()
 	}
+	d.diag.GainPulses = int(d.gain)
 }
 
+// The chip clamps the output to these codes when the input is outside its range.
+const (
+	saturatedHigh uint32 = 0x7FFFFF
+	saturatedLow  uint32 = 0x800000
+)
+
 // read performs a simple read of 24 bits
 func (d *Device) read() uint32 {
+	value, _ := d.readContext(context.Background())
+	return value
+}
+
+// readContext is read giving up when ctx is done, see readData.
+func (d *Device) readContext(ctx context.Context) (uint32, error) {
+	if d.exclusiveClock {
+		clockMutex.Lock()
+		defer clockMutex.Unlock()
+	}
+	return d.readBits(ctx, 24)
+}
+
+// readBits reads the n most significant bits of a conversion, the rest of the 24 bits are still clocked
+// (but not sampled) otherwise the chip would take the next pulses as gain selection.
+// The returned value is aligned as a full 24 bit conversion with the unread bits set to 0.
+func (d *Device) readBits(ctx context.Context, n int) (uint32, error) {
+	d.clockStretched = false
+	value, err := d.readData(ctx, n)
+	d.setGainAndChannel()
+	if err == nil && d.clockStretched {
+		err = fmt.Errorf("pulse longer than %s: %w", d.maxPulseWidth, ErrClockStretched)
+	}
+	return value, err
+}
+
+// readData is readBits without the gain pulses.
+// If ctx is done in the middle of the read the rest of the bits are clocked out anyway, otherwise the chip
+// would be left mid conversion and take the pulses of the next read as the rest of this one.
+func (d *Device) readData(ctx context.Context, n int) (uint32, error) {
 	var value uint32
+	d.lastBits = d.lastBits[:0]
 	for i := 0; i < 24; i++ {
+		if err := ctx.Err(); err != nil {
+			for ; i < 24+d.statusBits; i++ {
+				d.tick()
+			}
+			return 0, err
+		}
 		d.tick()
 		value = value << 1
-		if d.dt.Get() {
+		if i >= n {
+			continue
+		}
+		if d.getBit() {
 			value = value | 1
 		}
 	}
-	d.setGainAndChannel()
+	if d.statusBits > 0 {
+		d.readStatus(value)
+	}
+	if d.tracer != nil {
+		d.tracer(TraceEvent{Kind: TraceValue, Value: value})
+	}
+	return value, nil
+}
+
+// getBit samples DT, it must be called after a tick.
+func (d *Device) getBit() bool {
+	bit := d.dt.Get()
+	if d.captureBits {
+		d.lastBits = append(d.lastBits, bit)
+	}
+	if d.tracer != nil {
+		d.tracer(TraceEvent{Kind: TraceBit, Bit: bit})
+	}
+	return bit
+}
+
+// sample performs avg of <SmoothingFactor> reads and returns it sign extended.
+func (d *Device) sample() int64 {
+	return d.sampleN(d.smoothingFactor)
+}
+
+// sampleN performs avg of n reads and returns it sign extended.
+func (d *Device) sampleN(n int) int64 {
+	d.discardPending()
+	d.saturated = false
+	value, err := d.averageConversions(context.Background(), n)
+	if err != nil {
+		d.diag.LastError = err
+	}
 	return value
 }
 
+// measure is sample with the configured filters applied.
+func (d *Device) measure() int64 {
+	if d.cached() {
+		return d.lastSample
+	}
+	if d.readTimeout > 0 {
+		ctx, cancel := d.timeoutContext()
+		defer cancel()
+		raw, err := d.sampleContext(ctx, d.smoothingFactor)
+		if err != nil {
+			d.diag.LastError = d.contextError(err)
+			return d.lastSample
+		}
+		return d.record(raw)
+	}
+	return d.record(d.sample())
+}
+
+// cached reports whether the last read is recent enough to be reused, see SetMinReadInterval.
+func (d *Device) cached() bool {
+	return d.minReadInterval > 0 && d.hasLastSample && d.clock().Now().Sub(d.lastReadTime) < d.minReadInterval
+}
+
+// record runs a new sample through the filters and keeps it as the most recent read.
+func (d *Device) record(raw int64) int64 {
+	d.warmup.add(d, raw)
+	d.lastSample = d.lowPass.apply(raw)
+	d.hasLastSample = true
+	d.stability.update(d.lastSample)
+	d.lastReadTime = d.clock().Now()
+	return d.lastSample
+}
+
+// SetMinReadInterval makes reads performed less than interval after the previous one return its value instead
+// of reading the chip again, there is no point in reading faster than the chip converts (10 or 80 times per
+// second) so this protects from loops hammering the device. 0 disables it.
+func (d *Device) SetMinReadInterval(interval time.Duration) {
+	d.opMutex.Lock()
+	defer d.opMutex.Unlock()
+	d.minReadInterval = interval
+}
+
+// LastReadTime returns when the most recent Read completed, zero time if there was none, useful
+// to detect stale data if the task reading dies.
+func (d *Device) LastReadTime() time.Time {
+	d.opMutex.Lock()
+	defer d.opMutex.Unlock()
+	return d.lastReadTime
+}
+
+// notInitialized reports whether the device did not go through New, NewWithOptions or Initialize, recording
+// ErrNotInitialized in Diagnostics if so. Every method reading the chip checks it first and, if the device was
+// not initialized, does not touch the chip and returns zero values, or ErrNotInitialized where it returns errors.
+func (d *Device) notInitialized() bool {
+	if d.initialized {
+		return false
+	}
+	d.diag.LastError = ErrNotInitialized
+	return true
+}
+
 // Read performs avg of <SmoothingFactor> reads and returns that, adjusted for offset and tare.
+// A Device that did not go through New, NewWithOptions or Initialize returns 0 and records ErrNotInitialized
+// in Diagnostics, see ReadContext to get the error. The same goes for every other method reading the chip,
+// calibrated reads, tare and calibration included, those returning an error return ErrNotInitialized.
 func (d *Device) Read() int64 {
 	d.opMutex.Lock()
 	defer d.opMutex.Unlock()
-	return toInt64(avg(d.smoothingFactor, d.read)) - d.offset - d.tare
+	if d.notInitialized() {
+		return 0
+	}
+	return d.measure() - d.offset - d.tare
+}
+
+// WasSaturated reports whether any of the conversions of the most recent read was clamped at the limits of
+// the ADC range, in which case the value returned is not the real weight but a floor/ceiling (ie: overload).
+func (d *Device) WasSaturated() bool {
+	d.opMutex.Lock()
+	defer d.opMutex.Unlock()
+	return d.saturated
+}
+
+// ReadCoarse performs a single read sampling only the <bits> most significant bits and returns it adjusted for
+// offset and tare, the bits not sampled are 0.
+// This is meant for quick over/under threshold decisions, the chip still needs all 24 clocks so the read
+// takes the same time, what you save is the averaging and you can stop caring about the noisy low bits.
+func (d *Device) ReadCoarse(bits int) int64 {
+	d.opMutex.Lock()
+	defer d.opMutex.Unlock()
+	if d.notInitialized() {
+		return 0
+	}
+	if bits < 1 || bits > 24 {
+		bits = 24
+	}
+	d.discardPending()
+	value, _ := d.readBits(context.Background(), bits)
+	if d.inverted {
+		value = invert(value)
+	}
+	return toInt64(value) - d.offset - d.tare
 }
 
 // ReadCalibrated performs avg of <SmoothingFactor> reads and returns that, adjusted for offset, tare and calibration.
@@ -142,26 +540,122 @@ func (d *Device) Read() int64 {
 func (d *Device) ReadCalibrated() int64 {
 	d.opMutex.Lock()
 	defer d.opMutex.Unlock()
-	offset := float64(d.offset) * d.calibrationFactor
-	tare := float64(d.tare) * d.calibrationFactor
-	return int64(float64(toInt64(avg(d.smoothingFactor, d.read)))*d.calibrationFactor - offset - tare)
+	if d.notInitialized() {
+		return 0
+	}
+	return d.calibrated(d.measure())
+}
+
+// ReadAverageOf is ReadCalibrated averaging n reads instead of <SmoothingFactor>, for the odd high precision read.
+func (d *Device) ReadAverageOf(n int) int64 {
+	d.opMutex.Lock()
+	defer d.opMutex.Unlock()
+	if d.notInitialized() {
+		return 0
+	}
+	return d.calibrated(d.sampleN(n))
+}
+
+// ReadOnce performs a single read, no averaging or outlier rejection, and returns it adjusted for offset, tare
+// and calibration, for control loops that can't wait for a burst.
+func (d *Device) ReadOnce() int64 {
+	d.opMutex.Lock()
+	defer d.opMutex.Unlock()
+	if d.notInitialized() {
+		return 0
+	}
+	return d.calibrated(d.sampleN(1))
+}
+
+// ReadNormalized performs avg of <SmoothingFactor> reads and returns it as a fraction of the chip's full scale,
+// from -1 for the lowest code to just under 1 for the highest, for DSP code that expects normalized samples.
+// It is the raw reading, not adjusted for offset, tare nor calibration and no filters are applied.
+func (d *Device) ReadNormalized() float64 {
+	d.opMutex.Lock()
+	defer d.opMutex.Unlock()
+	if d.notInitialized() {
+		return 0
+	}
+	return float64(d.sample()) / fullScale
+}
+
+// calibrated converts a raw value into a calibrated one, offset and tare are subtracted in raw units and
+// only then the result is scaled, so a tare always zeroes the calibrated value no matter the factor.
+func (d *Device) calibrated(raw int64) int64 {
+	return int64(d.weight(raw - d.offset - d.tare))
 }
 
 // Tare performs ... well.. tare? https://en.wikipedia.org/wiki/Tare_weight
+// tare is kept in raw units so it stays valid if the calibration factor changes afterwards.
+// Since the whole load is read again, taring again after adding something to the scale stacks, see TareAdd.
 func (d *Device) Tare() {
 	d.opMutex.Lock()
 	defer d.opMutex.Unlock()
-	d.tare = toInt64(avg(d.smoothingFactor, d.read)) - d.offset
+	if d.notInitialized() {
+		return
+	}
+	d.tare = d.sample() - d.offset
+	if d.tare < 0 { // this was a tare on a small value
+		d.tare = 0
+	}
+}
+
+// StartupTare is the usual startup in one call: it throws away <discard> conversions, the first ones after power
+// up or a change of gain wander while the chip settles, and then tares the empty scale so the device is ready
+// to weigh. A device that was not initialized is initialized first, see Initialize, so the discarded
+// conversions come after the settling wait and the baseline offset.
+func (d *Device) StartupTare(discard int) {
+	d.opMutex.Lock()
+	defer d.opMutex.Unlock()
+	if !d.initialized && d.initialize() != nil {
+		return
+	}
+	if discard > d.pendingDiscard {
+		d.pendingDiscard = discard
+	}
+	d.tare = d.sample() - d.offset
+	if d.tare < 0 { // this was a tare on a small value
+		d.tare = 0
+	}
+}
+
+// TareAdd adds the current reading, already adjusted for the existing tare, to the tare, this is the baker's
+// workflow of taring the bowl, adding an ingredient, taring again and adding the next.
+// Unlike Tare the result is not clamped at 0, so taking something off the scale reduces the tare.
+func (d *Device) TareAdd() {
+	d.opMutex.Lock()
+	defer d.opMutex.Unlock()
+	if d.notInitialized() {
+		return
+	}
+	// adding the reading on top of the tare is taking the whole load as tare, the only difference from Tare is
+	// the missing clamp
+	d.tare = d.sample() - d.offset
+}
+
+// TareFromLast performs tare using the value of the most recent read instead of reading again, which makes
+// a tare button feel instant when the application is already polling.
+func (d *Device) TareFromLast() error {
+	d.opMutex.Lock()
+	defer d.opMutex.Unlock()
+	if !d.hasLastSample {
+		return fmt.Errorf("tare from the last read: %w", ErrNoRead)
+	}
+	d.tare = d.lastSample - d.offset
 	if d.tare < 0 { // this was a tare on a small value
 		d.tare = 0
 	}
+	return nil
 }
 
 // Zero re-sets offset and tare for the load cell.
 func (d *Device) Zero() {
 	d.opMutex.Lock()
 	defer d.opMutex.Unlock()
-	d.offset = toInt64(avg(d.smoothingFactor, d.read))
+	if d.notInitialized() {
+		return
+	}
+	d.offset = d.sample()
 	d.tare = 0
 }
 
@@ -171,6 +665,7 @@ func (d *Device) Zero() {
 func (d *Device) GetCalibrationFactor() float64 {
 	d.opMutex.Lock()
 	defer d.opMutex.Unlock()
+	d.syncFactor()
 	return d.calibrationFactor
 }
 
@@ -183,23 +678,37 @@ func (d *Device) SetCalibrationFactor(factor float64) {
 
 func (d *Device) setCalibrationFactor(factor float64) {
 	d.calibrationFactor = factor
+	d.ratio = ratioFromFactor(factor)
+	d.factorStale = false
+	d.hasCalibration = true
+	d.calibrationTime = d.clock().Now()
 }
 
-// Calibrate takes the known correct weight of the current load and calculates a factor to correct for drift.
+// IsCalibrated reports whether a calibration factor was set or computed for this device.
+func (d *Device) IsCalibrated() bool {
+	d.opMutex.Lock()
+	defer d.opMutex.Unlock()
+	return d.hasCalibration
+}
+
+// Calibrate takes the known correct weight of the current load and calculates the calibration factor, which is
+// weight units per raw unit so reading back the same load with ReadGrams returns weightInGrams.
+// The load is read like Read does, adjusted for offset and tare, so zero the empty scale first.
 // It is recommended that you save this value once and set it on each ue of a new Device instance for a given
 // hardware to avoid having to perform the calibration again.
-// Performing this process with various weights improves accuracy.... supposedly, depends on the quality of the cell.
 func (d *Device) Calibrate(weightInGrams float64) (float64, error) {
 	d.opMutex.Lock()
 	defer d.opMutex.Unlock()
+	if d.notInitialized() {
+		return 0, ErrNotInitialized
+	}
 	if weightInGrams == 0 {
-		return 0, fmt.Errorf("weight needs to be > 0")
+		return 0, fmt.Errorf("calibrating: %w", ErrZeroWeight)
 	}
-	weight := weightInGrams * 1000
-	newCF := weight / (float64(toInt64(d.read())) * d.calibrationFactor)
-	if newCF == 0 {
-		return 0, fmt.Errorf("resulting calibration factor would be 0")
+	raw := d.sample() - d.offset - d.tare
+	if raw == 0 {
+		return 0, fmt.Errorf("the load reads as 0: %w", ErrZeroFactor)
 	}
-	d.setCalibrationFactor(newCF / 1)
+	d.setCalibrationFactor(weightInGrams / float64(raw))
 	return d.calibrationFactor, nil
 }
@@ -1,15 +1,22 @@
 package hx711
 
 import (
+	"context"
+	"errors"
+	"flag"
 	"fmt"
+	"math"
 	"math/bits"
 	"testing"
+	"time"
 )
 
 type counterDataPin struct {
 	countH, countL int
 	get            []bool
 	getIdx         int
+	// loop makes Get start over once all the loaded bits were read
+	loop bool
 }
 
 func (c *counterDataPin) loadBits(u []uint32, reset bool) {
@@ -34,6 +41,9 @@ func (c *counterDataPin) reset() {
 }
 
 func (c *counterDataPin) Get() bool {
+	if c.loop && c.getIdx == len(c.get) {
+		c.getIdx = 0
+	}
 	b := c.get[c.getIdx]
 	c.getIdx++
 	return b
@@ -50,23 +60,28 @@ func (c *counterDataPin) Low() {
 func TestDevice_Calibrate(t *testing.T) {
 	dtp := &counterDataPin{}
 	var someBbits []uint32
-	for i := 0; i < 20; i++ {
-		someBbits = append(someBbits, 500000+uint32(i))
+	for i := 0; i < 10; i++ {
+		someBbits = append(someBbits, 500100)
+	}
+	for i := 0; i < 10; i++ {
+		someBbits = append(someBbits, 496100)
 	}
 	dtp.loadBits(someBbits, false)
 	td := Device{
 		sck:               dtp,
 		dt:                dtp,
+		initialized:       true,
 		gain:              Gain128,
 		smoothingFactor:   10,
 		calibrationFactor: 1,
+		offset:            100,
 	}
 
 	v, err := td.Calibrate(495.00)
 	if err != nil {
 		t.Fatal(err)
 	}
-	cal1 := 0.9900000000
+	cal1 := 0.0009900000
 	// yes, this is a hack but much more understandable and easier that starting shifting bits again.
 	if fmt.Sprintf("%.10f", v) != fmt.Sprintf("%.10f", cal1) {
 		t.Logf("calibration result expected to be %.10f but is %.10f", cal1, v)
@@ -76,13 +91,14 @@ func TestDevice_Calibrate(t *testing.T) {
 	if err != nil {
 		t.Fatal(err)
 	}
-	cal2 := 1.0020181980
+	// the previous factor plays no part in the new one
+	cal2 := 0.0010000000
 	if fmt.Sprintf("%.10f", v) != fmt.Sprintf("%.10f", cal2) {
 		t.Logf("calibration result n2 expected to be %.10f but is %.10f", cal2, v)
 		t.FailNow()
 	}
 
-	if dtp.countL != dtp.countH || dtp.countL != (2+2*24) {
+	if dtp.countL != dtp.countH || dtp.countL != 20*(1+24) {
 		t.Logf("Gain is %d but tick was called %d times for High and %d times for Low", Gain128, dtp.countH, dtp.countL)
 		t.FailNow()
 	}
@@ -99,6 +115,7 @@ func TestDevice_Read(t *testing.T) {
 		td := Device{
 			sck:             dtp,
 			dt:              dtp,
+			initialized:     true,
 			gain:            g,
 			smoothingFactor: 10,
 		}
@@ -127,6 +144,7 @@ func TestDevice_read(t *testing.T) {
 		td := Device{
 			sck:             dtp,
 			dt:              dtp,
+			initialized:     true,
 			gain:            g,
 			smoothingFactor: 10,
 		}
@@ -148,8 +166,9 @@ func TestDevice_setGainAndChannel(t *testing.T) {
 	dtp := &counterDataPin{}
 	for _, g := range []gainLVL{Gain128, Gain64, Gain32} {
 		td := Device{
-			sck:  dtp,
-			gain: g,
+			initialized: true,
+			sck:         dtp,
+			gain:        g,
 		}
 		td.setGainAndChannel()
 		if dtp.countL != dtp.countH || dtp.countL != int(g) {
@@ -163,7 +182,8 @@ func TestDevice_setGainAndChannel(t *testing.T) {
 func TestDevice_tick(t *testing.T) {
 	dtp := &counterDataPin{}
 	td := Device{
-		sck: dtp,
+		initialized: true,
+		sck:         dtp,
 	}
 	td.tick()
 	if dtp.countL != dtp.countH || dtp.countL != 1 {
@@ -224,3 +244,523 @@ func Test_toInt64(t *testing.T) {
 		})
 	}
 }
+
+func TestDevice_ReadCoarse(t *testing.T) {
+	for _, bits := range []int{1, 4, 8, 16, 24} {
+		dtp := &counterDataPin{}
+		var value uint32 = 0b010110101100111000110101
+		dtp.loadBits([]uint32{value}, false)
+		td := Device{
+			sck:             dtp,
+			dt:              dtp,
+			initialized:     true,
+			gain:            Gain128,
+			smoothingFactor: 10,
+		}
+		v := td.ReadCoarse(bits)
+		mask := uint32(0xFFFFFF) << (24 - bits) & 0xFFFFFF
+		if v != int64(value&mask) {
+			t.Logf("reading %d bits expected %b but got %b", bits, value&mask, v)
+			t.FailNow()
+		}
+		if dtp.getIdx != bits {
+			t.Logf("reading %d bits but DT was sampled %d times", bits, dtp.getIdx)
+			t.FailNow()
+		}
+		if dtp.countL != dtp.countH || dtp.countL != 24+int(Gain128) {
+			t.Logf("tick was called %d times for High and %d times for Low, expected 25", dtp.countH, dtp.countL)
+			t.FailNow()
+		}
+	}
+}
+
+func TestDevice_ReadCoarse_inverted(t *testing.T) {
+	dtp := &counterDataPin{}
+	dtp.loadBits([]uint32{5000, 5000, 5000}, false)
+	td := Device{
+		sck:             dtp,
+		dt:              dtp,
+		initialized:     true,
+		gain:            Gain128,
+		smoothingFactor: 1,
+		inverted:        true,
+	}
+	td.Zero()
+	if v := td.Read(); v != 0 {
+		t.Logf("expected %d but got %d", 0, v)
+		t.FailNow()
+	}
+	if v := td.ReadCoarse(24); v != 0 {
+		t.Logf("expected the coarse read to be inverted like the offset and be %d but got %d", 0, v)
+		t.FailNow()
+	}
+}
+
+func TestDevice_WasSaturated(t *testing.T) {
+	dtp := &counterDataPin{}
+	dtp.loadBits([]uint32{saturatedHigh, saturatedHigh, 50000, 50000, saturatedLow, saturatedLow}, false)
+	td := Device{
+		sck:             dtp,
+		dt:              dtp,
+		initialized:     true,
+		gain:            Gain128,
+		smoothingFactor: 2,
+	}
+	if td.WasSaturated() {
+		t.Log("expected no saturation before any read")
+		t.FailNow()
+	}
+	if v := td.Read(); v != int64(saturatedHigh) || !td.WasSaturated() {
+		t.Logf("expected saturation at %d but got %d (saturated %v)", saturatedHigh, v, td.WasSaturated())
+		t.FailNow()
+	}
+	if td.Read(); td.WasSaturated() {
+		t.Log("expected saturation to clear on a read within range")
+		t.FailNow()
+	}
+	if v := td.Read(); v != -int64(saturatedLow) || !td.WasSaturated() {
+		t.Logf("expected saturation at %d but got %d (saturated %v)", -int64(saturatedLow), v, td.WasSaturated())
+		t.FailNow()
+	}
+}
+
+func TestDevice_TareFromLast(t *testing.T) {
+	dtp := &counterDataPin{}
+	dtp.loadBits([]uint32{50000, 50000, 50100, 50100}, false)
+	td := Device{
+		sck:             dtp,
+		dt:              dtp,
+		initialized:     true,
+		gain:            Gain128,
+		smoothingFactor: 2,
+		offset:          40000,
+	}
+	if err := td.TareFromLast(); err == nil {
+		t.Log("expected an error when taring from last before any read")
+		t.FailNow()
+	}
+	if v := td.Read(); v != 10000 {
+		t.Logf("expected %d but got %d", 10000, v)
+		t.FailNow()
+	}
+	ticks := dtp.countH
+	if err := td.TareFromLast(); err != nil {
+		t.Fatal(err)
+	}
+	if dtp.countH != ticks {
+		t.Logf("expected tare from last to not read but tick was called %d more times", dtp.countH-ticks)
+		t.FailNow()
+	}
+	if td.tare != 10000 {
+		t.Logf("expected tare to be %d but is %d", 10000, td.tare)
+		t.FailNow()
+	}
+	if v := td.Read(); v != 100 {
+		t.Logf("expected %d after tare but got %d", 100, v)
+		t.FailNow()
+	}
+}
+
+func TestDevice_Tare_calibrated(t *testing.T) {
+	for _, factor := range []float64{1, 0.1, 0.37, 3.3, 1234.5678, -0.0042} {
+		dtp := &counterDataPin{}
+		dtp.loadBits([]uint32{50003, 50003, 50003, 50003, 50003, 50003, 50003, 50003}, false)
+		td := Device{
+			sck:               dtp,
+			dt:                dtp,
+			initialized:       true,
+			gain:              Gain128,
+			smoothingFactor:   2,
+			offset:            3,
+			calibrationFactor: factor,
+		}
+		if v := td.ReadCalibrated(); v != int64(50000*factor) {
+			t.Logf("with factor %f expected %d before tare but got %d", factor, int64(50000*factor), v)
+			t.FailNow()
+		}
+		td.Tare()
+		if v := td.ReadCalibrated(); v != 0 {
+			t.Logf("with factor %f expected 0 after tare but got %d", factor, v)
+			t.FailNow()
+		}
+		// changing the factor after the tare must not un-zero the display
+		td.SetCalibrationFactor(factor * 7)
+		if v := td.ReadCalibrated(); v != 0 {
+			t.Logf("with factor %f expected 0 after tare and recalibration but got %d", factor*7, v)
+			t.FailNow()
+		}
+	}
+}
+
+// benchSmoothing allows running the read benchmarks with a different smoothing factor, ie:
+//
+//	go test -run '^$' -bench . -hx711.smoothing=100
+var benchSmoothing = flag.Int("hx711.smoothing", DefaultSmoothingFactor, "smoothing factor used by benchmarks")
+
+func benchDevice() *Device {
+	dtp := &counterDataPin{loop: true}
+	dtp.loadBits([]uint32{50000, 50010, 50005, 49995}, false)
+	return &Device{
+		sck:             dtp,
+		dt:              dtp,
+		initialized:     true,
+		gain:            Gain128,
+		smoothingFactor: *benchSmoothing,
+	}
+}
+
+func BenchmarkRead(b *testing.B) {
+	td := benchDevice()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		td.Read()
+	}
+}
+
+func BenchmarkReadRaw(b *testing.B) {
+	td := benchDevice()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		td.read()
+	}
+}
+
+func BenchmarkAvg(b *testing.B) {
+	values := []uint32{50000, 50010, 50005, 49995}
+	idx := 0
+	f := func() uint32 {
+		idx++
+		return values[idx%len(values)]
+	}
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		avg(*benchSmoothing, f)
+	}
+}
+
+func TestDevice_LastReadTime(t *testing.T) {
+	dtp := &counterDataPin{loop: true}
+	dtp.loadBits([]uint32{50000}, false)
+	td := Device{
+		sck:             dtp,
+		dt:              dtp,
+		initialized:     true,
+		gain:            Gain128,
+		smoothingFactor: 1,
+	}
+	if !td.LastReadTime().IsZero() {
+		t.Logf("expected zero time before any read but got %s", td.LastReadTime())
+		t.FailNow()
+	}
+	before := time.Now()
+	td.Read()
+	first := td.LastReadTime()
+	if first.Before(before) {
+		t.Logf("expected last read time %s to be after %s", first, before)
+		t.FailNow()
+	}
+	time.Sleep(time.Millisecond)
+	td.ReadCalibrated()
+	if second := td.LastReadTime(); !second.After(first) {
+		t.Logf("expected last read time to advance from %s but is %s", first, second)
+		t.FailNow()
+	}
+}
+
+func TestDevice_SetMinReadInterval(t *testing.T) {
+	dtp := &counterDataPin{}
+	dtp.loadBits([]uint32{1000, 2000}, false)
+	clk := newFakeClock()
+	td := Device{
+		sck:             dtp,
+		dt:              dtp,
+		initialized:     true,
+		gain:            Gain128,
+		smoothingFactor: 1,
+		clk:             clk,
+	}
+	td.SetMinReadInterval(100 * time.Millisecond)
+	if v := td.Read(); v != 1000 {
+		t.Logf("expected %d but got %d", 1000, v)
+		t.FailNow()
+	}
+	clk.Advance(99 * time.Millisecond)
+	if v := td.Read(); v != 1000 {
+		t.Logf("expected cached %d within the interval but got %d", 1000, v)
+		t.FailNow()
+	}
+	if dtp.countH != 24+int(Gain128) {
+		t.Logf("expected a single read but tick was called %d times", dtp.countH)
+		t.FailNow()
+	}
+	clk.Advance(time.Millisecond)
+	if v := td.Read(); v != 2000 {
+		t.Logf("expected a new read of %d after the interval but got %d", 2000, v)
+		t.FailNow()
+	}
+}
+
+func TestDevice_ReadAverageOf(t *testing.T) {
+	dtp := &counterDataPin{loop: true}
+	dtp.loadBits([]uint32{1100}, false)
+	td := Device{
+		sck:               dtp,
+		dt:                dtp,
+		initialized:       true,
+		gain:              Gain128,
+		smoothingFactor:   10,
+		calibrationFactor: 2,
+		offset:            50,
+		tare:              50,
+	}
+	if v := td.ReadAverageOf(200); v != 2000 {
+		t.Logf("expected %d but got %d", 2000, v)
+		t.FailNow()
+	}
+	if dtp.countH != 200*(24+int(Gain128)) {
+		t.Logf("expected 200 reads but tick was called %d times", dtp.countH)
+		t.FailNow()
+	}
+	if td.smoothingFactor != 10 {
+		t.Logf("expected smoothing factor to stay %d but is %d", 10, td.smoothingFactor)
+		t.FailNow()
+	}
+}
+
+func TestDevice_ReadOnce(t *testing.T) {
+	dtp := &counterDataPin{}
+	dtp.loadBits([]uint32{1100, 5000}, false)
+	td := Device{
+		sck:               dtp,
+		dt:                dtp,
+		initialized:       true,
+		gain:              Gain64,
+		smoothingFactor:   10,
+		calibrationFactor: 3,
+		offset:            60,
+		tare:              40,
+	}
+	if v := td.ReadOnce(); v != 3000 {
+		t.Logf("expected %d but got %d", 3000, v)
+		t.FailNow()
+	}
+	if dtp.countL != dtp.countH || dtp.countL != 24+int(Gain64) {
+		t.Logf("expected a single read but tick was called %d times for High and %d times for Low", dtp.countH, dtp.countL)
+		t.FailNow()
+	}
+}
+
+func TestDevice_Calibrate_readBack(t *testing.T) {
+	dtp := &counterDataPin{loop: true}
+	dtp.loadBits([]uint32{1234567}, false)
+	td := Device{
+		sck:               dtp,
+		dt:                dtp,
+		initialized:       true,
+		gain:              Gain128,
+		smoothingFactor:   3,
+		calibrationFactor: 1,
+		offset:            34567,
+		tare:              200000,
+	}
+	for _, w := range []float64{500, 1000, 2.5} {
+		if _, err := td.Calibrate(w); err != nil {
+			t.Fatal(err)
+		}
+		if g := td.ReadGrams(); math.Abs(g-w) > 1e-9 {
+			t.Logf("calibrated with %f but read back %f", w, g)
+			t.FailNow()
+		}
+		if c := td.ReadCalibrated(); c != int64(w) {
+			t.Logf("calibrated with %f but ReadCalibrated returned %d", w, c)
+			t.FailNow()
+		}
+	}
+}
+
+func TestDevice_TareAdd(t *testing.T) {
+	dtp := &counterDataPin{}
+	// empty, bowl, bowl+flour, bowl+flour+water read twice each
+	dtp.loadBits([]uint32{1000, 1000, 3000, 3000, 8000, 8000, 11500, 11500, 11500, 11500}, false)
+	td := Device{
+		sck:             dtp,
+		dt:              dtp,
+		initialized:     true,
+		gain:            Gain128,
+		smoothingFactor: 2,
+	}
+	td.Zero()
+	td.Tare()
+	if td.tare != 2000 {
+		t.Logf("expected the bowl to be the tare %d but it is %d", 2000, td.tare)
+		t.FailNow()
+	}
+	td.TareAdd()
+	if td.tare != 7000 {
+		t.Logf("expected the flour to be added to the tare %d but it is %d", 7000, td.tare)
+		t.FailNow()
+	}
+	if v := td.Read(); v != 3500 {
+		t.Logf("expected only the water to be read %d but got %d", 3500, v)
+		t.FailNow()
+	}
+	// taring again re-reads the whole load so it stacks too
+	td.Tare()
+	if v := td.tare; v != 10500 {
+		t.Logf("expected tare to be %d but is %d", 10500, v)
+		t.FailNow()
+	}
+}
+
+func TestDevice_Read_notInitialized(t *testing.T) {
+	dtp := &counterDataPin{}
+	dtp.loadBits([]uint32{1000}, false)
+	td := Device{
+		sck:             dtp,
+		dt:              dtp,
+		gain:            Gain128,
+		smoothingFactor: 1,
+	}
+	if v := td.Read(); v != 0 || !errors.Is(td.Diagnostics().LastError, ErrNotInitialized) {
+		t.Logf("expected 0 and %v but got %d and %v", ErrNotInitialized, v, td.Diagnostics().LastError)
+		t.FailNow()
+	}
+	if _, err := td.ReadContext(context.Background()); !errors.Is(err, ErrNotInitialized) {
+		t.Logf("expected %v but got %v", ErrNotInitialized, err)
+		t.FailNow()
+	}
+	td.calibrationFactor, td.offset, td.tare = 2, 100, 10
+	reads := map[string]func() float64{
+		"ReadGrams":      td.ReadGrams,
+		"ReadCalibrated": func() float64 { return float64(td.ReadCalibrated()) },
+		"ReadOnce":       func() float64 { return float64(td.ReadOnce()) },
+		"ReadMilligrams": func() float64 { return float64(td.ReadMilligrams()) },
+		"ReadAverageOf":  func() float64 { return float64(td.ReadAverageOf(2)) },
+		"ReadWith":       func() float64 { return float64(td.ReadWith(ReadOpts{ApplyCalibration: true})) },
+		"SNR":            func() float64 { return td.SNR(2) },
+		"DriftSinceZero": func() float64 { return float64(td.DriftSinceZero()) },
+	}
+	for name, read := range reads {
+		td.diag.LastError = nil
+		if v := read(); v != 0 || !errors.Is(td.Diagnostics().LastError, ErrNotInitialized) {
+			t.Logf("%s expected 0 and %v but got %f and %v", name, ErrNotInitialized, v, td.Diagnostics().LastError)
+			t.FailNow()
+		}
+	}
+	td.diag.LastError = nil
+	if s := td.MaintenanceStatus(); s != (MaintenanceStatus{}) || !errors.Is(td.Diagnostics().LastError, ErrNotInitialized) {
+		t.Logf("expected a zero maintenance status and %v but got %+v and %v", ErrNotInitialized, s, td.Diagnostics().LastError)
+		t.FailNow()
+	}
+	if _, err := td.Calibrate(100); !errors.Is(err, ErrNotInitialized) {
+		t.Logf("expected %v calibrating but got %v", ErrNotInitialized, err)
+		t.FailNow()
+	}
+	td.Tare()
+	td.Zero()
+	if td.offset != 100 || td.tare != 10 || td.calibrationFactor != 2 {
+		t.Logf("expected offset, tare and calibration to be left alone but got %d, %d and %f", td.offset, td.tare, td.calibrationFactor)
+		t.FailNow()
+	}
+	if dtp.countH != 0 {
+		t.Logf("expected the chip to be left alone but tick was called %d times", dtp.countH)
+		t.FailNow()
+	}
+	if _, err := NewPreset(CalibrationState{}).ReadContext(context.Background()); err != nil {
+		t.Logf("expected a preset device to count as initialized but got %v", err)
+		t.FailNow()
+	}
+}
+
+func TestDevice_EstimatedReadDuration(t *testing.T) {
+	for _, g := range []gainLVL{Gain128, Gain64, Gain32} {
+		clk := newFakeClock()
+		dtp := &counterDataPin{}
+		dtp.loadBits([]uint32{1000, 1000, 1000, 1000, 1000}, false)
+		td := Device{
+			sck:             dtp,
+			dt:              dtp,
+			gain:            g,
+			smoothingFactor: 3,
+			tickDelay:       time.Microsecond,
+			clk:             clk,
+			initialized:     true,
+		}
+		td.stateChanged()
+		estimate := td.EstimatedReadDuration()
+		start := clk.Now()
+		td.Read()
+		if took := clk.Now().Sub(start); took != estimate {
+			t.Logf("at gain %d expected the read to take %s but it took %s", g, estimate, took)
+			t.FailNow()
+		}
+		if want := time.Duration(dtp.countH) * 2 * td.tickDelay; estimate != want {
+			t.Logf("at gain %d expected %d ticks, %s, but estimated %s", g, dtp.countH, want, estimate)
+			t.FailNow()
+		}
+		if td.EstimatedReadDuration() >= estimate {
+			t.Log("expected the estimate to drop once the discard is done")
+			t.FailNow()
+		}
+	}
+}
+
+func TestDevice_StartupTare(t *testing.T) {
+	dtp := &counterDataPin{}
+	// 3 conversions wandering while the chip settles, then the empty platform
+	dtp.loadBits([]uint32{9000, 5000, 3000, 1100, 1100}, false)
+	td := Device{
+		sck:               dtp,
+		dt:                dtp,
+		gain:              Gain128,
+		smoothingFactor:   2,
+		calibrationFactor: 1,
+		offset:            100,
+		initialized:       true,
+	}
+	td.StartupTare(3)
+	if dtp.getIdx != 5*24 {
+		t.Logf("expected 3 discarded and 2 averaged conversions but %d bits were read", dtp.getIdx)
+		t.FailNow()
+	}
+	if td.tare != 1000 {
+		t.Logf("expected tare %d but got %d", 1000, td.tare)
+		t.FailNow()
+	}
+	if td.pendingDiscard != 0 {
+		t.Logf("expected no discard left but got %d", td.pendingDiscard)
+		t.FailNow()
+	}
+}
+
+func TestDevice_ReadNormalized(t *testing.T) {
+	tests := []struct {
+		name string
+		code uint32
+		want float64
+	}{
+		{name: "min", code: saturatedLow, want: -1},
+		{name: "max", code: saturatedHigh, want: 1 - 1.0/fullScale},
+		{name: "mid", code: 0, want: 0},
+		{name: "half", code: 1 << 22, want: 0.5},
+		{name: "negative half", code: 0xC00000, want: -0.5},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dtp := &counterDataPin{}
+			dtp.loadBits([]uint32{tt.code}, false)
+			td := Device{
+				sck:             dtp,
+				dt:              dtp,
+				initialized:     true,
+				gain:            Gain128,
+				smoothingFactor: 1,
+				offset:          100,
+			}
+			if v := td.ReadNormalized(); v != tt.want {
+				t.Logf("expected %f but got %f", tt.want, v)
+				t.FailNow()
+			}
+		})
+	}
+}
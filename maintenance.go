@@ -0,0 +1,203 @@
+package hx711
+
+import (
+	"math"
+	"time"
+)
+
+// DriftSinceZero reads the cell, which should be unloaded, and returns how far it is from the offset
+// stored by the last Zero (or New), in raw units.
+// A baseline that moved far from offset means the cell needs to be zeroed or calibrated again.
+func (d *Device) DriftSinceZero() int64 {
+	d.opMutex.Lock()
+	defer d.opMutex.Unlock()
+	if d.notInitialized() {
+		return 0
+	}
+	return d.driftSinceZero()
+}
+
+func (d *Device) driftSinceZero() int64 {
+	drift := d.sample() - d.offset
+	if d.onDrift != nil && abs64(drift) > d.driftThreshold {
+		d.onDrift(drift)
+	}
+	return drift
+}
+
+// SetDriftThreshold sets a function to be called by DriftSinceZero and MaintenanceStatus when the absolute drift
+// exceeds threshold, while the device is locked so it must not call the device, ie: to Zero it, do that once the
+// read returned. Pass a nil fn to disable it.
+func (d *Device) SetDriftThreshold(threshold int64, fn func(drift int64)) {
+	d.opMutex.Lock()
+	defer d.opMutex.Unlock()
+	d.driftThreshold = threshold
+	d.onDrift = fn
+}
+
+func abs64(v int64) int64 {
+	if v < 0 {
+		return -v
+	}
+	return v
+}
+
+// MaintenanceStatus tells if a deployed scale needs attention, see Device.MaintenanceStatus.
+type MaintenanceStatus struct {
+	// Drift is the distance of the unloaded reading from offset, see DriftSinceZero.
+	Drift int64
+	// Calibrated is false if the device was never calibrated.
+	Calibrated bool
+	// SinceCalibration is the time elapsed since the calibration factor was set, 0 if never calibrated.
+	SinceCalibration time.Duration
+	// RecalibrationRecommended is true when the device was never calibrated or any of the thresholds set
+	// with SetMaintenanceThresholds was exceeded.
+	RecalibrationRecommended bool
+}
+
+// SetMaintenanceThresholds sets the absolute drift (raw units) and the calibration age above which
+// MaintenanceStatus recommends recalibrating, 0 disables each threshold.
+func (d *Device) SetMaintenanceThresholds(maxDrift int64, maxCalibrationAge time.Duration) {
+	d.opMutex.Lock()
+	defer d.opMutex.Unlock()
+	d.maxDrift = maxDrift
+	d.maxCalibrationAge = maxCalibrationAge
+}
+
+// MaintenanceStatus reads the cell, which should be unloaded, and reports whether it should be recalibrated.
+func (d *Device) MaintenanceStatus() MaintenanceStatus {
+	d.opMutex.Lock()
+	defer d.opMutex.Unlock()
+	if d.notInitialized() {
+		return MaintenanceStatus{}
+	}
+	status := MaintenanceStatus{
+		Drift:      d.driftSinceZero(),
+		Calibrated: d.hasCalibration,
+	}
+	if d.hasCalibration {
+		status.SinceCalibration = d.clock().Now().Sub(d.calibrationTime)
+	}
+	status.RecalibrationRecommended = !status.Calibrated ||
+		(d.maxDrift > 0 && abs64(status.Drift) > d.maxDrift) ||
+		(d.maxCalibrationAge > 0 && status.SinceCalibration > d.maxCalibrationAge)
+	return status
+}
+
+// SNR performs n single reads of the current load and returns their signal to noise ratio in dB, that is
+// 20*log10(mean/stddev) of the reads adjusted for offset and tare. Put a known weight on the cell first,
+// a low value for a good weight means a bad install or noisy power. A perfectly steady signal returns +Inf.
+func (d *Device) SNR(n int) float64 {
+	d.opMutex.Lock()
+	defer d.opMutex.Unlock()
+	if d.notInitialized() {
+		return 0
+	}
+	if n < 1 {
+		n = 1
+	}
+	d.discardPending()
+	values := make([]float64, n)
+	var sum float64
+	for i := range values {
+		values[i] = float64(toInt64(d.conversion()) - d.offset - d.tare)
+		sum += values[i]
+	}
+	mean := sum / float64(n)
+	var squares float64
+	for _, v := range values {
+		squares += (v - mean) * (v - mean)
+	}
+	stddev := math.Sqrt(squares / float64(n))
+	if stddev == 0 {
+		return math.Inf(1)
+	}
+	return 20 * math.Log10(math.Abs(mean)/stddev)
+}
+
+// floatingStdDev is the standard deviation, in raw counts, above which conversions look like a floating DT,
+// 1/16 of the range, no connected cell is anywhere close to that noisy.
+const floatingStdDev = fullScale / 16
+
+// DetectFloating performs samples single conversions and reports whether they look like noise over the whole
+// range, which is what a disconnected DT floating around gives, rather than a settled signal, even a noisy one.
+// At least 2 samples are taken, 10 or more give a reliable answer.
+func (d *Device) DetectFloating(samples int) bool {
+	d.opMutex.Lock()
+	defer d.opMutex.Unlock()
+	if d.notInitialized() {
+		return false
+	}
+	if samples < 2 {
+		samples = 2
+	}
+	d.discardPending()
+	values := make([]float64, samples)
+	var sum float64
+	for i := range values {
+		values[i] = float64(toInt64(d.conversion()))
+		sum += values[i]
+	}
+	mean := sum / float64(samples)
+	var squares float64
+	for _, v := range values {
+		squares += (v - mean) * (v - mean)
+	}
+	return math.Sqrt(squares/float64(samples)) > floatingStdDev
+}
+
+// ScheduledVerify runs VerifyCalibration against refGrams every interval in the background and calls onFail
+// when the error is beyond tolerancePct, for installations that can lower a reference weight onto the cell,
+// the reference must be on the cell when the check runs. It returns a function that stops the checks.
+func (d *Device) ScheduledVerify(interval time.Duration, refGrams float64, tolerancePct float64, onFail func()) (stop func()) {
+	done := make(chan struct{})
+	stopped := make(chan struct{})
+	go func() {
+		defer close(stopped)
+		for {
+			select {
+			case <-done:
+				return
+			case <-d.clock().After(interval):
+			}
+			if _, ok := d.VerifyCalibration(refGrams, tolerancePct); !ok && onFail != nil {
+				onFail()
+			}
+		}
+	}()
+	return func() {
+		close(done)
+		<-stopped
+	}
+}
+
+// MeasureCreep logs how the reading of a constant load drifts over time, load cells creep a little under a held
+// load and how much is part of their spec. It reads like Read, without the low-pass filter, every
+// sampleInterval for durationSeconds, the first read right away and the last one at the end, and returns the
+// reads adjusted for offset and tare. The device is only held during each read. An interval <= 0 is a second.
+func (d *Device) MeasureCreep(durationSeconds int, sampleInterval time.Duration) []int64 {
+	if sampleInterval <= 0 {
+		sampleInterval = time.Second
+	}
+	duration := time.Duration(durationSeconds) * time.Second
+	if duration < 0 {
+		duration = 0
+	}
+	d.opMutex.Lock()
+	if d.notInitialized() {
+		d.opMutex.Unlock()
+		return nil
+	}
+	d.opMutex.Unlock()
+	values := make([]int64, 0, duration/sampleInterval+1)
+	start := d.clock().Now()
+	for i := 0; time.Duration(i)*sampleInterval <= duration; i++ {
+		if wait := start.Add(time.Duration(i) * sampleInterval).Sub(d.clock().Now()); wait > 0 {
+			d.clock().Sleep(wait)
+		}
+		d.opMutex.Lock()
+		values = append(values, d.sample()-d.offset-d.tare)
+		d.opMutex.Unlock()
+	}
+	return values
+}
@@ -0,0 +1,242 @@
+package hx711
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+	"time"
+)
+
+func TestDevice_DriftSinceZero(t *testing.T) {
+	dtp := &counterDataPin{}
+	// baseline starts at 50000 and slowly creeps up
+	dtp.loadBits([]uint32{50000, 50000, 50020, 50020, 50080, 50080}, false)
+	td := Device{
+		sck:             dtp,
+		dt:              dtp,
+		initialized:     true,
+		gain:            Gain128,
+		smoothingFactor: 2,
+	}
+	td.Zero()
+	var alarms []int64
+	td.SetDriftThreshold(50, func(drift int64) {
+		alarms = append(alarms, drift)
+	})
+	if drift := td.DriftSinceZero(); drift != 20 {
+		t.Logf("expected drift to be %d but is %d", 20, drift)
+		t.FailNow()
+	}
+	if len(alarms) != 0 {
+		t.Logf("expected no alarm for a drift under threshold but got %v", alarms)
+		t.FailNow()
+	}
+	if drift := td.DriftSinceZero(); drift != 80 {
+		t.Logf("expected drift to be %d but is %d", 80, drift)
+		t.FailNow()
+	}
+	if len(alarms) != 1 || alarms[0] != 80 {
+		t.Logf("expected one alarm with a drift of 80 but got %v", alarms)
+		t.FailNow()
+	}
+}
+
+func TestDevice_MaintenanceStatus(t *testing.T) {
+	dtp := &counterDataPin{}
+	dtp.loadBits([]uint32{1000, 1000, 1050, 1200, 1000}, false)
+	clk := newFakeClock()
+	td := Device{
+		sck:             dtp,
+		dt:              dtp,
+		initialized:     true,
+		gain:            Gain128,
+		smoothingFactor: 1,
+		clk:             clk,
+	}
+	td.Zero()
+	td.SetMaintenanceThresholds(100, 24*time.Hour)
+	if s := td.MaintenanceStatus(); !s.RecalibrationRecommended || s.Calibrated {
+		t.Logf("expected recalibration to be recommended for an uncalibrated device, got %+v", s)
+		t.FailNow()
+	}
+
+	td.SetCalibrationFactor(2)
+	clk.Advance(time.Hour)
+	if s := td.MaintenanceStatus(); s.RecalibrationRecommended || s.Drift != 50 || s.SinceCalibration != time.Hour {
+		t.Logf("expected no recalibration for a small drift and a recent calibration, got %+v", s)
+		t.FailNow()
+	}
+	if s := td.MaintenanceStatus(); !s.RecalibrationRecommended || s.Drift != 200 {
+		t.Logf("expected recalibration to be recommended for a large drift, got %+v", s)
+		t.FailNow()
+	}
+	clk.Advance(24 * time.Hour)
+	if s := td.MaintenanceStatus(); !s.RecalibrationRecommended || s.Drift != 0 {
+		t.Logf("expected recalibration to be recommended for an old calibration, got %+v", s)
+		t.FailNow()
+	}
+}
+
+func TestDevice_SNR(t *testing.T) {
+	tests := []struct {
+		name   string
+		values []uint32
+		want   float64
+	}{
+		// mean 1000, stddev 10
+		{name: "40dB", values: []uint32{1090, 1110, 1090, 1110}, want: 40},
+		// mean 1000, stddev 100
+		{name: "20dB", values: []uint32{1000, 1200, 1000, 1200}, want: 20},
+		{name: "steady", values: []uint32{1100, 1100, 1100, 1100}, want: math.Inf(1)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dtp := &counterDataPin{}
+			dtp.loadBits(tt.values, false)
+			td := Device{
+				sck:         dtp,
+				dt:          dtp,
+				initialized: true,
+				gain:        Gain128,
+				offset:      100,
+			}
+			if got := td.SNR(len(tt.values)); math.Abs(got-tt.want) > 1e-9 && got != tt.want {
+				t.Logf("expected SNR to be %fdB but got %fdB", tt.want, got)
+				t.FailNow()
+			}
+		})
+	}
+}
+
+func TestDevice_ScheduledVerify(t *testing.T) {
+	clk := newFakeClock()
+	dtp := &counterDataPin{}
+	// 100g at 0.01g per count, then it drifts to 110g
+	dtp.loadBits([]uint32{10000, 11000}, false)
+	td := &Device{
+		sck:               dtp,
+		dt:                dtp,
+		initialized:       true,
+		gain:              Gain128,
+		smoothingFactor:   1,
+		calibrationFactor: 0.01,
+		clk:               clk,
+	}
+	failures := make(chan struct{}, 2)
+	stop := td.ScheduledVerify(time.Hour, 100, 1, func() {
+		failures <- struct{}{}
+	})
+	defer stop()
+
+	advance := func() {
+		// wait for the check to be scheduled
+		for clk.Waiters() == 0 {
+			time.Sleep(time.Millisecond)
+		}
+		clk.Advance(time.Hour)
+	}
+	advance()
+	advance()
+	select {
+	case <-failures:
+	case <-time.After(time.Second):
+		t.Log("expected the drifted reading to trip onFail")
+		t.FailNow()
+	}
+	select {
+	case <-failures:
+		t.Log("expected only the drifted reading to trip onFail")
+		t.FailNow()
+	default:
+	}
+	if dtp.getIdx != len(dtp.get) {
+		t.Logf("expected 2 checks but %d bits were read", dtp.getIdx)
+		t.FailNow()
+	}
+}
+
+func TestDevice_DetectFloating(t *testing.T) {
+	rnd := rand.New(rand.NewSource(711))
+	random := make([]uint32, 20)
+	for i := range random {
+		random[i] = uint32(rnd.Intn(1 << 24))
+	}
+	settled := make([]uint32, 20)
+	for i := range settled {
+		// a noisy but connected cell
+		settled[i] = uint32(500000 + rnd.Intn(2000) - 1000)
+	}
+	tests := []struct {
+		name   string
+		values []uint32
+		want   bool
+	}{
+		{name: "floating", values: random, want: true},
+		{name: "settled", values: settled, want: false},
+		{name: "around 0", values: []uint32{0xFFFFF0, 0x000010, 0xFFFFF8, 0x000008}, want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dtp := &counterDataPin{}
+			dtp.loadBits(tt.values, false)
+			td := Device{
+				sck:         dtp,
+				dt:          dtp,
+				initialized: true,
+				gain:        Gain128,
+			}
+			if got := td.DetectFloating(len(tt.values)); got != tt.want {
+				t.Logf("expected %v but got %v", tt.want, got)
+				t.FailNow()
+			}
+		})
+	}
+}
+
+// timedPin records the time of the clock each time a conversion starts being read.
+type timedPin struct {
+	*counterDataPin
+	clk   *fakeClock
+	times []time.Time
+}
+
+func (p *timedPin) Get() bool {
+	if p.getIdx%24 == 0 {
+		p.times = append(p.times, p.clk.Now())
+	}
+	return p.counterDataPin.Get()
+}
+
+func TestDevice_MeasureCreep(t *testing.T) {
+	clk := newFakeClock()
+	dtp := &counterDataPin{}
+	// the reading creeps up under the same load
+	codes := []uint32{1100, 1102, 1104, 1106, 1108, 1110, 1112}
+	dtp.loadBits(codes, false)
+	pin := &timedPin{counterDataPin: dtp, clk: clk}
+	td := &Device{
+		sck:             dtp,
+		dt:              pin,
+		initialized:     true,
+		gain:            Gain128,
+		smoothingFactor: 1,
+		offset:          100,
+		clk:             clk,
+	}
+	start := clk.Now()
+	values := td.MeasureCreep(60, 10*time.Second)
+	if len(values) != 7 {
+		t.Logf("expected %d reads but got %d", 7, len(values))
+		t.FailNow()
+	}
+	for i, v := range values {
+		if want := toInt64(codes[i]) - 100; v != want {
+			t.Logf("expected read %d to be %d but got %d", i, want, v)
+			t.FailNow()
+		}
+		if want := start.Add(time.Duration(i) * 10 * time.Second); !pin.times[i].Equal(want) {
+			t.Logf("expected read %d at %s but it was at %s", i, want, pin.times[i])
+			t.FailNow()
+		}
+	}
+}
@@ -0,0 +1,78 @@
+package hx711
+
+import "time"
+
+// DefaultSampleRate is the conversion rate in Hz of a hx711 with the RATE pin low, which is how most boards come.
+const DefaultSampleRate = 10
+
+// DefaultTickDelay is how long each level of a clock pulse is held by default.
+const DefaultTickDelay = time.Microsecond
+
+// DefaultSmoothingFactor is the amount of reads averaged per Read when no WithSmoothingFactor option is passed.
+const DefaultSmoothingFactor = 10
+
+// Option configures a Device built by NewWithOptions.
+type Option func(d *Device)
+
+// WithGain selects gain and channel, defaults to Gain128.
+func WithGain(g gainLVL) Option {
+	return func(d *Device) {
+		d.SetGainAndChannel(g)
+	}
+}
+
+// WithSmoothingFactor sets the amount of reads averaged on each Read, defaults to DefaultSmoothingFactor.
+func WithSmoothingFactor(smoothingFactor int) Option {
+	return func(d *Device) {
+		d.smoothingFactor = smoothingFactor
+	}
+}
+
+// WithSettlingWait sets how long to wait for the chip to settle before the baseline read, 400ms is a good value.
+func WithSettlingWait(wait time.Duration) Option {
+	return func(d *Device) {
+		d.settlingWait = wait
+	}
+}
+
+// WithReadyFunc replaces the check used to know if the chip has a conversion ready (by default DT being low),
+// useful for boards with inverted logic or needing extra checks.
+func WithReadyFunc(ready func(dt DT) bool) Option {
+	return func(d *Device) {
+		d.readyFunc = ready
+	}
+}
+
+// WithSkipBaseline skips the baseline read that sets the offset during initialization, offset is left at 0
+// until set, ie: when restoring a saved calibration state right after creating the device.
+func WithSkipBaseline() Option {
+	return func(d *Device) {
+		d.skipBaseline = true
+	}
+}
+
+// WithBaselineBursts makes the baseline read during initialization take bursts reads of <SmoothingFactor>
+// conversions each, the ones further than tolerance raw units from the median are discarded as glitches and
+// the rest averaged into offset. A negative tolerance is taken as 0, only the bursts equal to the median are kept.
+func WithBaselineBursts(bursts int, tolerance int64) Option {
+	return func(d *Device) {
+		if tolerance < 0 {
+			tolerance = 0
+		}
+		d.baselineBursts = bursts
+		d.baselineTolerance = tolerance
+	}
+}
+
+// NewWithOptions returns a device configured with the passed options and initialized with the passed ports,
+// like New if the device is not appropriately connected this might hang. See Initialize to check the baseline.
+func NewWithOptions(sck SCK, dt DT, opts ...Option) *Device {
+	d := &Device{sck: sck, dt: dt, gain: Gain128, smoothingFactor: DefaultSmoothingFactor, calibrationFactor: 1,
+		sampleRate: DefaultSampleRate, tickDelay: DefaultTickDelay}
+	for _, opt := range opts {
+		opt(d)
+	}
+	// a saturated baseline is still the best we've got, Initialize can be called again to check
+	_ = d.Initialize()
+	return d
+}
@@ -0,0 +1,186 @@
+package hx711
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+// loadReady prepends a low DT level so the wait for the chip to be ready in initialization passes.
+func (c *counterDataPin) loadReady() {
+	c.get = append([]bool{false}, c.get...)
+}
+
+func TestNewWithOptions(t *testing.T) {
+	dtp := &counterDataPin{}
+	// the first read after initialization is discarded
+	someBits := []uint32{9999}
+	for i := 0; i < 5; i++ {
+		someBits = append(someBits, 50000)
+	}
+	dtp.loadBits(someBits, false)
+	dtp.loadReady()
+
+	wait := 20 * time.Millisecond
+	start := time.Now()
+	td := NewWithOptions(dtp, dtp, WithGain(Gain64), WithSmoothingFactor(5), WithSettlingWait(wait))
+	if elapsed := time.Since(start); elapsed < wait {
+		t.Logf("expected initialization to take at least %s but took %s", wait, elapsed)
+		t.FailNow()
+	}
+	if td.settlingWait != wait || td.gain != Gain64 || td.smoothingFactor != 5 {
+		t.Logf("options not applied, got settling %s, gain %d, smoothing %d", td.settlingWait, td.gain, td.smoothingFactor)
+		t.FailNow()
+	}
+	if td.offset != 50000 {
+		t.Logf("expected offset to be %d but is %d", 50000, td.offset)
+		t.FailNow()
+	}
+	// one gain selection before the ready wait plus a discarded read and 5 reads
+	if dtp.countL != dtp.countH || dtp.countL != int(Gain64)+6*(24+int(Gain64)) {
+		t.Logf("tick was called %d times for High and %d times for Low", dtp.countH, dtp.countL)
+		t.FailNow()
+	}
+}
+
+func TestNew_settlingWaitMillis(t *testing.T) {
+	dtp := &counterDataPin{}
+	dtp.loadBits([]uint32{9999, 50000}, false)
+	dtp.loadReady()
+	td := New(dtp, dtp, Gain128, 1, 15)
+	if td.settlingWait != 15*time.Millisecond {
+		t.Logf("expected settling wait of 15ms but got %s", td.settlingWait)
+		t.FailNow()
+	}
+}
+
+func TestNewWithOptions_WithReadyFunc(t *testing.T) {
+	dtp := &counterDataPin{}
+	dtp.loadBits([]uint32{9999, 50000}, false)
+	// inverted logic, the board reports ready with DT high twice in a row
+	dtp.get = append([]bool{false, true, true}, dtp.get...)
+	calls := 0
+	td := NewWithOptions(dtp, dtp, WithSmoothingFactor(1), WithReadyFunc(func(dt DT) bool {
+		calls++
+		return dt.Get() && dt.Get()
+	}))
+	if calls != 2 {
+		t.Logf("expected the ready predicate to be called %d times but was called %d", 2, calls)
+		t.FailNow()
+	}
+	if td.offset != 50000 {
+		t.Logf("expected offset to be %d but is %d", 50000, td.offset)
+		t.FailNow()
+	}
+}
+
+func TestNewWithOptions_WithSkipBaseline(t *testing.T) {
+	dtp := &counterDataPin{}
+	dtp.loadReady()
+	td := NewWithOptions(dtp, dtp, WithSmoothingFactor(5), WithSkipBaseline())
+	if td.offset != 0 {
+		t.Logf("expected offset to be left at 0 but is %d", td.offset)
+		t.FailNow()
+	}
+	// only the gain selection before the ready wait
+	if dtp.countL != dtp.countH || dtp.countL != int(Gain128) {
+		t.Logf("expected no reads but tick was called %d times for High and %d times for Low", dtp.countH, dtp.countL)
+		t.FailNow()
+	}
+	if dtp.getIdx != 1 {
+		t.Logf("expected only the ready check to sample DT but it was sampled %d times", dtp.getIdx)
+		t.FailNow()
+	}
+}
+
+func TestNewWithOptions_WithBaselineBursts(t *testing.T) {
+	dtp := &counterDataPin{}
+	// the first read after initialization is discarded, then the first burst catches a power up glitch
+	dtp.loadBits([]uint32{9999, 90000, 90000, 50000, 50002, 50010, 50010, 49990, 49990}, false)
+	dtp.loadReady()
+	td := NewWithOptions(dtp, dtp, WithSmoothingFactor(2), WithBaselineBursts(4, 100))
+	if td.offset != 50000 {
+		t.Logf("expected offset to be %d but is %d", 50000, td.offset)
+		t.FailNow()
+	}
+	if dtp.getIdx != len(dtp.get) {
+		t.Logf("expected %d bits to be read but %d were", len(dtp.get), dtp.getIdx)
+		t.FailNow()
+	}
+}
+
+func TestNewWithOptions_WithBaselineBursts_negativeTolerance(t *testing.T) {
+	dtp := &counterDataPin{}
+	dtp.loadBits([]uint32{9999, 50000, 50004, 50000}, false)
+	dtp.loadReady()
+	td := NewWithOptions(dtp, dtp, WithSmoothingFactor(1), WithBaselineBursts(3, -5))
+	if td.offset != 50000 {
+		t.Logf("expected offset to be %d but is %d", 50000, td.offset)
+		t.FailNow()
+	}
+}
+
+func TestDevice_Initialize(t *testing.T) {
+	dtp := &counterDataPin{}
+	dtp.loadBits([]uint32{9999, 50000}, false)
+	dtp.loadReady()
+	td := NewWithOptions(dtp, dtp, WithSmoothingFactor(1))
+	td.SetTickDelay(0)
+	if td.offset != 50000 {
+		t.Logf("expected offset to be %d but is %d", 50000, td.offset)
+		t.FailNow()
+	}
+	td.tare = 300
+	td.SetCalibrationFactor(2)
+
+	// after a while the baseline moved, and the gain changed
+	dtp = &counterDataPin{}
+	td.sck, td.dt = dtp, dtp
+	dtp.loadBits([]uint32{9999, 51000}, false)
+	dtp.loadReady()
+	td.gain = Gain64
+	if err := td.Initialize(); err != nil {
+		t.Fatal(err)
+	}
+	if td.offset != 51000 || td.tare != 300 || td.GetCalibrationFactor() != 2 {
+		t.Logf("expected offset %d with tare and factor kept but got %d, %d and %f", 51000, td.offset, td.tare, td.GetCalibrationFactor())
+		t.FailNow()
+	}
+	// gain selection before the ready wait plus a discarded read and the baseline
+	if dtp.countL != dtp.countH || dtp.countL != int(Gain64)+2*(24+int(Gain64)) {
+		t.Logf("tick was called %d times for High and %d times for Low", dtp.countH, dtp.countL)
+		t.FailNow()
+	}
+
+	dtp = &counterDataPin{}
+	td.sck, td.dt = dtp, dtp
+	dtp.loadBits([]uint32{9999, saturatedHigh}, false)
+	dtp.loadReady()
+	if err := td.Initialize(); !errors.Is(err, ErrSaturated) {
+		t.Logf("expected %v for a saturated baseline but got %v", ErrSaturated, err)
+		t.FailNow()
+	}
+}
+
+func TestNewWithOptions_WithWarmup(t *testing.T) {
+	dtp := &counterDataPin{}
+	// the baseline while cold and the cell drifting up while it warms
+	dtp.loadBits([]uint32{9999, 50000, 50010, 50020, 50030, 50030}, false)
+	dtp.loadReady()
+	td := NewWithOptions(dtp, dtp, WithSmoothingFactor(1), WithWarmup())
+	td.SetTickDelay(0)
+	if v := td.Read(); v != 5 {
+		t.Logf("expected the offset to follow the warm-up and read %d but got %d", 5, v)
+		t.FailNow()
+	}
+	td.Read()
+	td.Read()
+	if offset := td.FinalizeWarmup(); offset != 50015 {
+		t.Logf("expected the finalized offset to be %d but is %d", 50015, offset)
+		t.FailNow()
+	}
+	if v := td.Read(); v != 15 || td.offset != 50015 {
+		t.Logf("expected the offset to stay at %d after finalizing and read %d but got %d and %d", 50015, 15, td.offset, v)
+		t.FailNow()
+	}
+}
@@ -0,0 +1,93 @@
+package hx711
+
+import "time"
+
+// outlierEvent is a conversion seen by average.
+type outlierEvent struct {
+	at       time.Time
+	rejected bool
+}
+
+// outlierWindow keeps the conversions of the last window to tell how many were rejected as outliers.
+type outlierWindow struct {
+	// window is how far back conversions are kept, 0 is disabled.
+	window    time.Duration
+	threshold float64
+	onExceed  func(rate float64)
+
+	events   []outlierEvent
+	rejected int
+	exceeded bool
+}
+
+// add records a conversion seen at now and drops the ones that fell out of the window.
+func (o *outlierWindow) add(now time.Time, rejected bool) {
+	o.events = append(o.events, outlierEvent{at: now, rejected: rejected})
+	if rejected {
+		o.rejected++
+	}
+	o.prune(now)
+	rate := o.rate()
+	if o.threshold <= 0 {
+		return
+	}
+	if rate > o.threshold {
+		if !o.exceeded && o.onExceed != nil {
+			o.onExceed(rate)
+		}
+		o.exceeded = true
+		return
+	}
+	o.exceeded = false
+}
+
+// prune drops the conversions older than window.
+func (o *outlierWindow) prune(now time.Time) {
+	cutoff := now.Add(-o.window)
+	i := 0
+	for ; i < len(o.events) && o.events[i].at.Before(cutoff); i++ {
+		if o.events[i].rejected {
+			o.rejected--
+		}
+	}
+	o.events = append(o.events[:0], o.events[i:]...)
+}
+
+// rate is the fraction of the conversions in the window that were rejected.
+func (o *outlierWindow) rate() float64 {
+	if len(o.events) == 0 {
+		return 0
+	}
+	return float64(o.rejected) / float64(len(o.events))
+}
+
+// SetOutlierWindow makes the device keep track of how many conversions were rejected as outliers during the
+// last window, see OutlierRate. A sudden rise usually means something changed, ie: a loose wire or EMI.
+// If threshold is > 0 and onExceed is not nil, onExceed is called with the rate when it goes above threshold,
+// once until it drops back, while the device is locked so it must not call the device.
+// Pass a window of 0 to stop tracking.
+func (d *Device) SetOutlierWindow(window time.Duration, threshold float64, onExceed func(rate float64)) {
+	d.opMutex.Lock()
+	defer d.opMutex.Unlock()
+	if window <= 0 {
+		d.outliers = outlierWindow{}
+		d.smoothing.observe = nil
+		return
+	}
+	d.outliers = outlierWindow{window: window, threshold: threshold, onExceed: onExceed}
+	d.smoothing.observe = func(step avgStep) {
+		d.outliers.add(d.clock().Now(), step.rejected)
+	}
+}
+
+// OutlierRate returns the fraction, from 0 to 1, of the conversions of the last window that were rejected as
+// outliers, see SetOutlierWindow. It is 0 when not tracking.
+func (d *Device) OutlierRate() float64 {
+	d.opMutex.Lock()
+	defer d.opMutex.Unlock()
+	if d.outliers.window == 0 {
+		return 0
+	}
+	d.outliers.prune(d.clock().Now())
+	return d.outliers.rate()
+}
@@ -0,0 +1,74 @@
+package hx711
+
+import (
+	"testing"
+	"time"
+)
+
+func TestDevice_OutlierRate(t *testing.T) {
+	clk := newFakeClock()
+	dtp := &counterDataPin{}
+	// a quiet read, then one where half the conversions jump
+	dtp.loadBits([]uint32{1000, 1001, 1000, 1002, 1000, 5000, 1000, 5000}, false)
+	td := Device{
+		sck:             dtp,
+		dt:              dtp,
+		gain:            Gain128,
+		smoothingFactor: 4,
+		initialized:     true,
+		clk:             clk,
+	}
+	var alerts []float64
+	td.SetOutlierWindow(time.Second, 0.2, func(rate float64) { alerts = append(alerts, rate) })
+	td.Read()
+	if r := td.OutlierRate(); r != 0 || len(alerts) != 0 {
+		t.Logf("expected no outliers on a quiet read but got a rate of %f and %d alerts", r, len(alerts))
+		t.FailNow()
+	}
+	clk.Advance(500 * time.Millisecond)
+	td.Read()
+	if r := td.OutlierRate(); r != 0.25 {
+		t.Logf("expected 2 of 8 conversions to be outliers but got a rate of %f", r)
+		t.FailNow()
+	}
+	if len(alerts) != 1 || alerts[0] != 0.25 {
+		t.Logf("expected a single alert at 0.25 but got %v", alerts)
+		t.FailNow()
+	}
+	// the quiet read falls out of the window
+	clk.Advance(700 * time.Millisecond)
+	if r := td.OutlierRate(); r != 0.5 {
+		t.Logf("expected only the last read in the window but got a rate of %f", r)
+		t.FailNow()
+	}
+	clk.Advance(time.Second)
+	if r := td.OutlierRate(); r != 0 {
+		t.Logf("expected an empty window but got a rate of %f", r)
+		t.FailNow()
+	}
+
+	td.SetOutlierWindow(0, 0, nil)
+	if td.smoothing.observe != nil || td.OutlierRate() != 0 {
+		t.Log("expected tracking to stop")
+		t.FailNow()
+	}
+}
+
+func Test_average_observe(t *testing.T) {
+	values := []uint32{1000, 1001, 5000, 1000}
+	for _, mode := range []SmoothingMode{SmoothingRunning, SmoothingMean, SmoothingMedian, SmoothingRecency} {
+		var rejected, seen int
+		cfg := avgConfig{mode: mode, observe: func(step avgStep) {
+			seen++
+			if step.rejected {
+				rejected++
+			}
+		}}
+		i := 0
+		average(len(values), func() uint32 { i++; return values[i-1] }, cfg)
+		if seen != len(values) || rejected != 1 {
+			t.Logf("mode %d: expected 1 of %d conversions rejected but got %d of %d", mode, len(values), rejected, seen)
+			t.FailNow()
+		}
+	}
+}
@@ -0,0 +1,43 @@
+package hx711
+
+// PeriphPin is the part of periph.io's gpio.PinIO the adapters need, L is gpio.Level, so this package does
+// not depend on periph.io, ie:
+//
+//	d := hx711.NewFromPeriph[gpio.Level](gpioreg.ByName("GPIO5"), gpioreg.ByName("GPIO6"))
+//
+// Configure DT as input with the right pull (pin.In(gpio.PullUp, gpio.NoEdge)) before using it.
+type PeriphPin[L ~bool] interface {
+	Out(l L) error
+	Read() L
+}
+
+// periphSCK drives SCK through a periph.io pin, Out errors are ignored as the pin is already known to work
+// by the time the chip is being clocked.
+type periphSCK[L ~bool] struct {
+	pin PeriphPin[L]
+}
+
+func (p periphSCK[L]) High() { _ = p.pin.Out(L(true)) }
+func (p periphSCK[L]) Low()  { _ = p.pin.Out(L(false)) }
+
+// periphDT reads DT through a periph.io pin.
+type periphDT[L ~bool] struct {
+	pin PeriphPin[L]
+}
+
+func (p periphDT[L]) Get() bool { return bool(p.pin.Read()) }
+
+// PeriphSCK adapts a periph.io pin to SCK.
+func PeriphSCK[L ~bool](pin PeriphPin[L]) SCK {
+	return periphSCK[L]{pin: pin}
+}
+
+// PeriphDT adapts a periph.io pin to DT.
+func PeriphDT[L ~bool](pin PeriphPin[L]) DT {
+	return periphDT[L]{pin: pin}
+}
+
+// NewFromPeriph is NewWithOptions for periph.io pins, ie: on a Raspberry Pi running Linux.
+func NewFromPeriph[L ~bool](sck, dt PeriphPin[L], opts ...Option) *Device {
+	return NewWithOptions(PeriphSCK(sck), PeriphDT(dt), opts...)
+}
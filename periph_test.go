@@ -0,0 +1,62 @@
+package hx711
+
+import "testing"
+
+// fakeLevel stands for periph.io's gpio.Level.
+type fakeLevel bool
+
+// fakePeriphPin records the levels set and returns the loaded ones, like a periph.io gpio.PinIO.
+type fakePeriphPin struct {
+	out  []fakeLevel
+	read []fakeLevel
+	idx  int
+}
+
+func (f *fakePeriphPin) Out(l fakeLevel) error {
+	f.out = append(f.out, l)
+	return nil
+}
+
+func (f *fakePeriphPin) Read() fakeLevel {
+	l := f.read[f.idx]
+	f.idx++
+	return l
+}
+
+func TestPeriphAdapters(t *testing.T) {
+	sckPin := &fakePeriphPin{}
+	sck := PeriphSCK[fakeLevel](sckPin)
+	sck.High()
+	sck.Low()
+	if len(sckPin.out) != 2 || sckPin.out[0] != true || sckPin.out[1] != false {
+		t.Logf("expected High and Low to set true and false but got %v", sckPin.out)
+		t.FailNow()
+	}
+	dtPin := &fakePeriphPin{read: []fakeLevel{true, false}}
+	dt := PeriphDT[fakeLevel](dtPin)
+	if !dt.Get() || dt.Get() {
+		t.Log("expected Get to return the levels read")
+		t.FailNow()
+	}
+}
+
+func TestNewFromPeriph(t *testing.T) {
+	dtp := &counterDataPin{}
+	dtp.loadBits([]uint32{9999, 50000}, false)
+	dtp.loadReady()
+	dtPin := &fakePeriphPin{}
+	for _, b := range dtp.get {
+		dtPin.read = append(dtPin.read, fakeLevel(b))
+	}
+	sckPin := &fakePeriphPin{}
+	td := NewFromPeriph[fakeLevel](sckPin, dtPin, WithSmoothingFactor(1))
+	if td.offset != 50000 {
+		t.Logf("expected offset to be %d but is %d", 50000, td.offset)
+		t.FailNow()
+	}
+	// gain selection before the ready wait plus a discarded read and the baseline, each pulse high then low
+	if pulses := int(Gain128) + 2*(24+int(Gain128)); len(sckPin.out) != 2*pulses {
+		t.Logf("expected %d levels set on SCK but got %d", 2*pulses, len(sckPin.out))
+		t.FailNow()
+	}
+}
@@ -0,0 +1,124 @@
+package hx711
+
+import (
+	"time"
+)
+
+// powerDownTime is how long SCK needs to be held high for the chip to power down, the datasheet says 60µs.
+const powerDownTime = 100 * time.Microsecond
+
+// PowerDown puts the chip in power down mode, reads will return garbage until PowerUp is called.
+func (d *Device) PowerDown() {
+	d.opMutex.Lock()
+	defer d.opMutex.Unlock()
+	d.powerDown()
+}
+
+func (d *Device) powerDown() {
+	d.sck.High()
+	d.clock().Sleep(powerDownTime)
+}
+
+// PowerUp wakes the chip from PowerDown, it resets to Gain128 so the reads needed to apply the device
+// gain are discarded from the next read.
+func (d *Device) PowerUp() {
+	d.opMutex.Lock()
+	defer d.opMutex.Unlock()
+	d.powerUp()
+}
+
+func (d *Device) powerUp() {
+	d.sck.Low()
+	d.wake()
+	d.stateChanged()
+}
+
+// SetWakePulses sets an amount of dummy clock pulses sent on PowerUp and Initialize before anything else, some
+// boards end up in weird states after a reset or power up unless the chip is clocked a few times. 0, the
+// default, sends none.
+func (d *Device) SetWakePulses(n int) {
+	d.opMutex.Lock()
+	defer d.opMutex.Unlock()
+	if n < 0 {
+		n = 0
+	}
+	d.wakePulses = n
+}
+
+// wake sends the wake pulses.
+func (d *Device) wake() {
+	for i := 0; i < d.wakePulses; i++ {
+		d.tick()
+	}
+}
+
+// ScheduledLowPowerRead reads every interval in the background keeping the chip powered down in between, for
+// battery powered loggers. Each cycle powers the chip up, waits for it to be ready, reads like Read, powers it
+// down and then calls fn with the value. The first read is done right away. It returns a function that stops
+// the reads, the chip is left powered down. Like Read, nothing is read from a Device that was not initialized,
+// fn is not called then, nor is it when the chip is not ready within the read timeout, see SetReadTimeout, the
+// error is recorded in Diagnostics instead.
+func (d *Device) ScheduledLowPowerRead(interval time.Duration, fn func(int64)) (stop func()) {
+	done := make(chan struct{})
+	stopped := make(chan struct{})
+	go func() {
+		defer close(stopped)
+		for {
+			if v, ok := d.lowPowerRead(); ok {
+				fn(v)
+			}
+			select {
+			case <-done:
+				return
+			case <-d.clock().After(interval):
+			}
+		}
+	}()
+	return func() {
+		close(done)
+		<-stopped
+	}
+}
+
+// lowPowerRead is a cycle of ScheduledLowPowerRead, it reports whether there is a value.
+func (d *Device) lowPowerRead() (int64, bool) {
+	d.opMutex.Lock()
+	defer d.opMutex.Unlock()
+	if d.notInitialized() {
+		return 0, false
+	}
+	d.powerUp()
+	ctx, cancel := d.timeoutContext()
+	defer cancel()
+	if err := d.waitReady(ctx); err != nil {
+		d.diag.LastError = d.contextError(err)
+		d.powerDown()
+		return 0, false
+	}
+	v := d.measure() - d.offset - d.tare
+	d.powerDown()
+	return v, true
+}
+
+// SetDiscardAfterStateChange sets how many reads are discarded after any change of gain, channel or power
+// state, the first read after a change is always wrong so at least 1 is discarded regardless of n.
+func (d *Device) SetDiscardAfterStateChange(n int) {
+	d.opMutex.Lock()
+	defer d.opMutex.Unlock()
+	d.discardAfterStateChange = n
+}
+
+// stateChanged marks that the next read needs to discard the reads following a state change.
+func (d *Device) stateChanged() {
+	d.pendingDiscard = d.discardAfterStateChange
+	if d.pendingDiscard < 1 {
+		d.pendingDiscard = 1
+	}
+}
+
+// discardPending performs and throws away the reads pending after a state change.
+func (d *Device) discardPending() {
+	for ; d.pendingDiscard > 0; d.pendingDiscard-- {
+		d.conversion()
+	}
+}
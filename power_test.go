@@ -0,0 +1,195 @@
+package hx711
+
+import (
+	"testing"
+	"time"
+)
+
+func TestDevice_discardAfterStateChange(t *testing.T) {
+	changes := map[string]func(d *Device){
+		"gain": func(d *Device) {
+			d.SetGainAndChannel(Gain64)
+		},
+		"power": func(d *Device) {
+			d.PowerDown()
+			d.PowerUp()
+		},
+		"initialization": func(d *Device) {
+			d.dt.(*counterDataPin).loadReady()
+			d.initialize()
+		},
+	}
+	for name, change := range changes {
+		for _, discard := range []int{0, 1, 3} {
+			dtp := &counterDataPin{}
+			bits := []uint32{}
+			for i := 0; i < discard || i < 1; i++ {
+				bits = append(bits, 9999)
+			}
+			// the read after the change (a baseline in the case of initialization)
+			bits = append(bits, 1000)
+			dtp.loadBits(bits, false)
+			td := &Device{
+				sck:             dtp,
+				dt:              dtp,
+				initialized:     true,
+				gain:            Gain128,
+				smoothingFactor: 1,
+			}
+			td.SetDiscardAfterStateChange(discard)
+			change(td)
+			if name == "initialization" {
+				if td.offset != 1000 {
+					t.Logf("%s with %d discards: expected offset %d but got %d", name, discard, 1000, td.offset)
+					t.FailNow()
+				}
+				continue
+			}
+			if v := td.Read(); v != 1000 {
+				t.Logf("%s with %d discards: expected %d but got %d", name, discard, 1000, v)
+				t.FailNow()
+			}
+			if dtp.getIdx != len(dtp.get) {
+				t.Logf("%s with %d discards: expected %d bits to be read but %d were", name, discard, len(dtp.get), dtp.getIdx)
+				t.FailNow()
+			}
+		}
+	}
+}
+
+// powerLogPin remembers whether SCK was last left high, which is the chip being powered down.
+type powerLogPin struct {
+	*counterDataPin
+	high bool
+}
+
+func (p *powerLogPin) High() {
+	p.counterDataPin.High()
+	p.high = true
+}
+
+func (p *powerLogPin) Low() {
+	p.counterDataPin.Low()
+	p.high = false
+}
+
+func TestDevice_ScheduledLowPowerRead(t *testing.T) {
+	clk := newFakeClock()
+	dtp := &counterDataPin{}
+	// each cycle waits for ready, then the discard after power up and the read
+	for _, v := range []uint32{1100, 1200} {
+		dtp.get = append(dtp.get, true, false)
+		dtp.loadBits([]uint32{9999, v}, false)
+	}
+	sck := &powerLogPin{counterDataPin: dtp}
+	td := &Device{
+		sck:             sck,
+		dt:              dtp,
+		initialized:     true,
+		gain:            Gain128,
+		smoothingFactor: 1,
+		offset:          100,
+		clk:             clk,
+	}
+	type cycle struct {
+		value      int64
+		poweredOff bool
+		ticks      int
+	}
+	cycles := make(chan cycle, 2)
+	stop := td.ScheduledLowPowerRead(time.Minute, func(v int64) {
+		cycles <- cycle{value: v, poweredOff: sck.high, ticks: dtp.countH}
+	})
+	for i, want := range []int64{1000, 1100} {
+		if i > 0 {
+			// wait for the next cycle to be scheduled
+			for clk.Waiters() == 0 {
+				time.Sleep(time.Millisecond)
+			}
+			clk.Advance(time.Minute)
+		}
+		var c cycle
+		select {
+		case c = <-cycles:
+		case <-time.After(time.Second):
+			t.Logf("cycle %d never ran", i)
+			t.FailNow()
+		}
+		// power down pulse plus 2 conversions per cycle
+		ticks := (i + 1) * (1 + 2*(24+int(Gain128)))
+		if c.value != want || !c.poweredOff || c.ticks != ticks {
+			t.Logf("cycle %d expected %d powered off after %d pulses but got %+v", i, want, ticks, c)
+			t.FailNow()
+		}
+	}
+	for clk.Waiters() == 0 {
+		time.Sleep(time.Millisecond)
+	}
+	stop()
+	if dtp.getIdx != len(dtp.get) {
+		t.Logf("expected %d bits to be read but %d were", len(dtp.get), dtp.getIdx)
+		t.FailNow()
+	}
+}
+
+func TestDevice_SetGainAndChannel_unchanged(t *testing.T) {
+	dtp := &counterDataPin{}
+	dtp.loadBits([]uint32{1000}, false)
+	td := &Device{
+		sck:             dtp,
+		dt:              dtp,
+		initialized:     true,
+		gain:            Gain64,
+		smoothingFactor: 1,
+	}
+	td.SetGainAndChannel(Gain64)
+	if td.pendingDiscard != 0 {
+		t.Logf("expected no discard for the same gain but got %d", td.pendingDiscard)
+		t.FailNow()
+	}
+	if v := td.Read(); v != 1000 || dtp.getIdx != 24 {
+		t.Logf("expected a single conversion of %d but got %d from %d bits", 1000, v, dtp.getIdx)
+		t.FailNow()
+	}
+	td.applyGain(Gain64)
+	if dtp.getIdx != 24 {
+		t.Logf("expected no discard reading at the same gain but %d bits were read", dtp.getIdx)
+		t.FailNow()
+	}
+}
+
+func TestDevice_SetWakePulses(t *testing.T) {
+	dtp := &counterDataPin{}
+	td := &Device{
+		sck:             dtp,
+		dt:              dtp,
+		initialized:     true,
+		gain:            Gain128,
+		smoothingFactor: 1,
+	}
+	td.SetWakePulses(5)
+	td.PowerDown()
+	dtp.reset()
+	td.PowerUp()
+	// SCK goes low to power up and then the 5 pulses
+	if dtp.countH != 5 || dtp.countL != 6 {
+		t.Logf("expected 5 wake pulses but tick was called %d times for High and %d times for Low", dtp.countH, dtp.countL)
+		t.FailNow()
+	}
+
+	dtp = &counterDataPin{}
+	dtp.loadReady()
+	td = &Device{
+		sck:          dtp,
+		dt:           dtp,
+		initialized:  true,
+		gain:         Gain64,
+		skipBaseline: true,
+	}
+	td.SetWakePulses(3)
+	td.initialize()
+	if want := 3 + int(Gain64); dtp.countH != want || dtp.countL != want {
+		t.Logf("expected %d pulses but tick was called %d times for High and %d times for Low", want, dtp.countH, dtp.countL)
+		t.FailNow()
+	}
+}
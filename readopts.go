@@ -0,0 +1,34 @@
+package hx711
+
+// ReadOpts tunes a single ReadWith call without changing the configuration of the device.
+type ReadOpts struct {
+	// SkipOutlier averages every conversion, outliers included.
+	SkipOutlier bool
+	// SampleCount is the amount of conversions averaged, 0 means <SmoothingFactor>.
+	SampleCount int
+	// ApplyCalibration returns the value multiplied by the calibration factor, like ReadCalibrated.
+	ApplyCalibration bool
+}
+
+// ReadWith reads like Read, adjusted for offset and tare, tuned by opts. It always reads, bypassing the low-pass
+// filter and the minimum read interval, and does not change what LastReadTime or TareFromLast see.
+func (d *Device) ReadWith(opts ReadOpts) int64 {
+	d.opMutex.Lock()
+	defer d.opMutex.Unlock()
+	if d.notInitialized() {
+		return 0
+	}
+	n := opts.SampleCount
+	if n <= 0 {
+		n = d.smoothingFactor
+	}
+	cfg := d.smoothing
+	cfg.keepOutliers = opts.SkipOutlier
+	d.discardPending()
+	d.saturated = false
+	raw := toInt64(average(n, d.conversion, cfg))
+	if opts.ApplyCalibration {
+		return d.calibrated(raw)
+	}
+	return raw - d.offset - d.tare
+}
@@ -0,0 +1,46 @@
+package hx711
+
+import "testing"
+
+func TestDevice_ReadWith(t *testing.T) {
+	tests := []struct {
+		name  string
+		opts  ReadOpts
+		reads int
+		want  int64
+	}{
+		// 1000, 1400 is dropped as an outlier, then (1000+1000)/2
+		{name: "defaults", opts: ReadOpts{}, reads: 3, want: 900},
+		// (1000+1400)/2, then (1200+1000)/2
+		{name: "keep outliers", opts: ReadOpts{SkipOutlier: true}, reads: 3, want: 1000},
+		{name: "single calibrated", opts: ReadOpts{SampleCount: 1, ApplyCalibration: true}, reads: 1, want: 1800},
+		{name: "keep outliers calibrated", opts: ReadOpts{SkipOutlier: true, SampleCount: 2, ApplyCalibration: true}, reads: 2, want: 2200},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dtp := &counterDataPin{}
+			dtp.loadBits([]uint32{1000, 1400, 1000}, false)
+			td := Device{
+				sck:               dtp,
+				dt:                dtp,
+				initialized:       true,
+				gain:              Gain128,
+				smoothingFactor:   3,
+				calibrationFactor: 2,
+				offset:            100,
+			}
+			if v := td.ReadWith(tt.opts); v != tt.want {
+				t.Logf("expected %d but got %d", tt.want, v)
+				t.FailNow()
+			}
+			if dtp.getIdx != tt.reads*24 {
+				t.Logf("expected %d conversions but %d bits were read", tt.reads, dtp.getIdx)
+				t.FailNow()
+			}
+			if td.hasLastSample || td.smoothing.keepOutliers {
+				t.Log("expected the device to be left as it was")
+				t.FailNow()
+			}
+		})
+	}
+}
@@ -0,0 +1,52 @@
+package hx711
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strconv"
+)
+
+// RecordReads writes every raw conversion, as the sampler returned it, to w as one hex number per line, so
+// a session can be fed back with ReplaySampler to reproduce it offline. nil stops recording.
+// Write errors are recorded in Diagnostics.
+func (d *Device) RecordReads(w io.Writer) {
+	d.opMutex.Lock()
+	defer d.opMutex.Unlock()
+	d.recorder = w
+}
+
+// recordConversion writes value to the recorder if there is one.
+func (d *Device) recordConversion(value uint32) {
+	if d.recorder == nil {
+		return
+	}
+	if _, err := fmt.Fprintf(d.recorder, "%06x\n", value); err != nil {
+		d.diag.LastError = fmt.Errorf("recording reads: %w", err)
+	}
+}
+
+// replaySampler is a Sampler returning the conversions recorded with RecordReads.
+type replaySampler struct {
+	s *bufio.Scanner
+}
+
+// ReplaySampler returns a Sampler that feeds back the conversions recorded with RecordReads, in order,
+// once they run out it returns io.EOF.
+func ReplaySampler(r io.Reader) Sampler {
+	return &replaySampler{s: bufio.NewScanner(r)}
+}
+
+func (r *replaySampler) ReadConversion() (uint32, error) {
+	if !r.s.Scan() {
+		if err := r.s.Err(); err != nil {
+			return 0, err
+		}
+		return 0, io.EOF
+	}
+	value, err := strconv.ParseUint(r.s.Text(), 16, 24)
+	if err != nil {
+		return 0, fmt.Errorf("replaying %q: %w", r.s.Text(), err)
+	}
+	return uint32(value), nil
+}
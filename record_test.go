@@ -0,0 +1,50 @@
+package hx711
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"testing"
+)
+
+func TestDevice_RecordReads(t *testing.T) {
+	dtp := &counterDataPin{}
+	dtp.loadBits([]uint32{1100, 1300, 900, 0xFFFF00, 0xFFFF10, 1000}, false)
+	newDevice := func() *Device {
+		return &Device{
+			sck:               dtp,
+			dt:                dtp,
+			initialized:       true,
+			gain:              Gain128,
+			smoothingFactor:   2,
+			calibrationFactor: 0.5,
+			offset:            100,
+		}
+	}
+	var session bytes.Buffer
+	td := newDevice()
+	td.RecordReads(&session)
+	var recorded []float64
+	for i := 0; i < 3; i++ {
+		recorded = append(recorded, td.ReadGrams())
+	}
+	td.RecordReads(nil)
+
+	replayed := newDevice()
+	replayed.SetSampler(ReplaySampler(bytes.NewReader(session.Bytes())))
+	for i, want := range recorded {
+		if got := replayed.ReadGrams(); got != want {
+			t.Logf("expected replayed read %d to be %f but got %f", i, want, got)
+			t.FailNow()
+		}
+	}
+	if diag := replayed.Diagnostics(); diag.FailedConversions != 0 {
+		t.Logf("expected no failed conversions replaying but got %+v", diag)
+		t.FailNow()
+	}
+	replayed.Read()
+	if diag := replayed.Diagnostics(); !errors.Is(diag.LastError, io.EOF) {
+		t.Logf("expected %v once the session ran out but got %v", io.EOF, diag.LastError)
+		t.FailNow()
+	}
+}
@@ -0,0 +1,71 @@
+package hx711
+
+import "time"
+
+// Report is everything a display usually needs from a read, see ReadReport.
+type Report struct {
+	// Raw is the read adjusted for offset and tare, like Read returns.
+	Raw int64
+	// Grams is Raw in the calibration unit, like ReadGrams returns.
+	Grams float64
+	// Stable is whether the reads have settled, including this one, see SetStabilityHysteresis.
+	Stable bool
+	// Timestamp is when the read completed, like LastReadTime.
+	Timestamp time.Time
+}
+
+// ReadReport performs avg of <SmoothingFactor> reads, like Read, and returns it as raw, weight and stability in
+// one go, all from the same read, instead of calling Read, ReadGrams and StabilityState which would read 2
+// times and could disagree with each other. Like Read, a Device that was not initialized returns a zero Report.
+func (d *Device) ReadReport() Report {
+	d.opMutex.Lock()
+	defer d.opMutex.Unlock()
+	return d.report()
+}
+
+// report is ReadReport without locking.
+func (d *Device) report() Report {
+	if d.notInitialized() {
+		return Report{}
+	}
+	raw := d.measure() - d.offset - d.tare
+	return Report{
+		Raw:       raw,
+		Grams:     d.weight(raw),
+		Stable:    d.stability.state == Stable,
+		Timestamp: d.lastReadTime,
+	}
+}
+
+// Sample is a read as plain values only, meant to be sent to a collector with whatever encoding the user
+// likes (protobuf, flatbuffers, JSON...), see ReadSample.
+type Sample struct {
+	// Raw is the read adjusted for offset and tare, like Read returns.
+	Raw int64
+	// Grams is Raw in the calibration unit, like ReadGrams returns.
+	Grams float64
+	// TimestampUnixNano is when the read completed, in nanoseconds since the unix epoch.
+	TimestampUnixNano int64
+	// Gain is the amplification the read was taken at: 128, 64 or 32 (channel B).
+	Gain int
+	// Stable is whether the reads have settled, including this one, see SetStabilityHysteresis.
+	Stable bool
+}
+
+// ReadSample is ReadReport returned as a Sample. Like Read, a Device that was not initialized returns a zero
+// Sample.
+func (d *Device) ReadSample() Sample {
+	d.opMutex.Lock()
+	defer d.opMutex.Unlock()
+	r := d.report()
+	if !d.initialized {
+		return Sample{}
+	}
+	return Sample{
+		Raw:               r.Raw,
+		Grams:             r.Grams,
+		TimestampUnixNano: r.Timestamp.UnixNano(),
+		Gain:              int(gainFactor(d.gain)),
+		Stable:            r.Stable,
+	}
+}
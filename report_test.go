@@ -0,0 +1,69 @@
+package hx711
+
+import (
+	"testing"
+	"time"
+)
+
+func TestDevice_ReadReport(t *testing.T) {
+	clk := newFakeClock()
+	clk.Advance(time.Hour)
+	dtp := &counterDataPin{}
+	dtp.loadBits([]uint32{1100, 1100, 1100, 1100}, false)
+	td := Device{
+		sck:               dtp,
+		dt:                dtp,
+		gain:              Gain128,
+		smoothingFactor:   2,
+		calibrationFactor: 0.5,
+		offset:            100,
+		initialized:       true,
+		clk:               clk,
+		stability:         stability{samples: 2, enterTolerance: 5, exitTolerance: 10},
+	}
+	r := td.ReadReport()
+	if r.Raw != 1000 || r.Grams != 500 || r.Stable || !r.Timestamp.Equal(clk.Now()) {
+		t.Logf("unexpected first report %+v", r)
+		t.FailNow()
+	}
+	r = td.ReadReport()
+	if r.Raw != 1000 || r.Grams != 500 || !r.Stable {
+		t.Logf("expected the second report to be stable, got %+v", r)
+		t.FailNow()
+	}
+	if dtp.getIdx != 4*24 {
+		t.Logf("expected 4 conversions but %d bits were read", dtp.getIdx)
+		t.FailNow()
+	}
+	if r.Timestamp != td.LastReadTime() {
+		t.Logf("expected the timestamp to be the last read time, got %s", r.Timestamp)
+		t.FailNow()
+	}
+}
+
+func TestDevice_ReadSample(t *testing.T) {
+	clk := newFakeClock()
+	dtp := &counterDataPin{}
+	dtp.loadBits([]uint32{1100, 1100}, false)
+	td := Device{
+		sck:               dtp,
+		dt:                dtp,
+		gain:              Gain64,
+		smoothingFactor:   2,
+		calibrationFactor: 0.5,
+		offset:            100,
+		initialized:       true,
+		clk:               clk,
+		stability:         stability{samples: 1, enterTolerance: 5, exitTolerance: 10},
+	}
+	want := Sample{Raw: 1000, Grams: 500, TimestampUnixNano: clk.Now().UnixNano(), Gain: 64, Stable: true}
+	if s := td.ReadSample(); s != want {
+		t.Logf("expected %+v but got %+v", want, s)
+		t.FailNow()
+	}
+	td.initialized = false
+	if s := td.ReadSample(); s != (Sample{}) {
+		t.Logf("expected a zero sample from a device not initialized but got %+v", s)
+		t.FailNow()
+	}
+}
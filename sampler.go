@@ -0,0 +1,134 @@
+package hx711
+
+import (
+	"context"
+	"fmt"
+)
+
+// Sampler provides raw conversions to a Device, it separates how conversions are obtained from the
+// filtering and calibration done by the Device. By default the pins passed to New are bit banged.
+type Sampler interface {
+	// ReadConversion returns a raw 24 bit conversion, as the chip sends it, with the right gain and channel
+	// selection for the next one.
+	ReadConversion() (uint32, error)
+}
+
+// SetSampler replaces the source of conversions, ie: for an SPI based reader, nil goes back to bit banging
+// the pins of the device. Methods that control the pins directly (ReadCoarse, ReadDataOnly, PulseGain,
+// PowerDown and PowerUp) keep doing so.
+// If the sampler fails, the error is recorded in Diagnostics and the last good conversion is used in its place.
+func (d *Device) SetSampler(s Sampler) {
+	d.opMutex.Lock()
+	defer d.opMutex.Unlock()
+	d.sampler = s
+}
+
+// SetMinSamples makes reads tolerate failed conversions, ie: on a marginal connection, the ones that fail are left
+// out of the average as long as at least n of the <SmoothingFactor> succeed, Diagnostics tells how many did.
+// If less than n succeed ReadContext returns an error wrapping ErrTooFewSamples and Read returns the average of
+// those that did, or the last good conversion if none, recording the error in Diagnostics.
+// 0, the default, needs every conversion: failed ones are replaced by the last good one and ReadContext gives up
+// on the first failure.
+func (d *Device) SetMinSamples(n int) {
+	d.opMutex.Lock()
+	defer d.opMutex.Unlock()
+	if n < 0 {
+		n = 0
+	}
+	d.minSamples = n
+}
+
+// averageConversions averages n conversions with the smoothing of the device, failed conversions are handled
+// as SetMinSamples describes. Once ctx is done the chip is not touched again.
+func (d *Device) averageConversions(ctx context.Context, n int) (int64, error) {
+	var err error
+	f := func() (uint32, error) {
+		// without a minimum the first failure of a cancellable read ends it
+		if ctx.Err() != nil || err != nil && d.minSamples == 0 && ctx.Done() != nil {
+			if err == nil {
+				err = ctx.Err()
+			}
+			return d.lastConversion, err
+		}
+		value, cerr := d.convert(ctx)
+		if err == nil {
+			err = cerr
+		}
+		return value, cerr
+	}
+	if d.minSamples == 0 {
+		value, ok := averageOf(n, func() (uint32, error) {
+			v, _ := f()
+			return v, nil
+		}, d.smoothing)
+		d.diag.Conversions = ok
+		return toInt64(value), err
+	}
+	value, ok := averageOf(n, f, d.smoothing)
+	d.diag.Conversions = ok
+	if ok == 0 {
+		value = d.lastConversion
+	}
+	if ok < d.minSamples {
+		return toInt64(value), fmt.Errorf("%d of %d conversions succeeded, %d needed: %w", ok, n, d.minSamples, ErrTooFewSamples)
+	}
+	return toInt64(value), nil
+}
+
+// conversion returns a conversion from the sampler of the device.
+func (d *Device) conversion() uint32 {
+	value, _ := d.convert(context.Background())
+	return value
+}
+
+// convert returns a conversion from the sampler of the device, if ctx can be done the chip is waited for
+// and the read is abandoned when it is, otherwise this is the plain read we always did.
+// On error the last good conversion is returned along with it.
+func (d *Device) convert(ctx context.Context) (uint32, error) {
+	var value uint32
+	var err error
+	switch {
+	case d.sampler != nil:
+		if err = ctx.Err(); err == nil {
+			value, err = d.sampler.ReadConversion()
+		}
+	case ctx.Done() == nil:
+		value, err = d.readContext(ctx)
+	default:
+		if err = d.waitReady(ctx); err == nil {
+			value, err = d.readContext(ctx)
+		}
+	}
+	if err != nil {
+		d.diag.FailedConversions++
+		d.diag.LastError = err
+		return d.lastConversion, err
+	}
+	d.recordConversion(value)
+	if value == saturatedHigh || value == saturatedLow {
+		d.saturated = true
+	}
+	if d.inverted {
+		value = invert(value)
+	}
+	d.lastConversion = value
+	return value, nil
+}
+
+// invert negates a 24 bit two's complement conversion, the lowest code has no positive counterpart so it
+// becomes the highest.
+func invert(value uint32) uint32 {
+	if value == saturatedLow {
+		return saturatedHigh
+	}
+	return uint32(-toInt64(value)) & 0xFFFFFF
+}
+
+// SetInverted negates every conversion, for load cells with swapped signal leads that read lower
+// as weight increases, so the rest of the pipeline sees readings increasing with weight.
+// Zero the device after changing this, the offset was taken with the previous sign.
+func (d *Device) SetInverted(inverted bool) {
+	d.opMutex.Lock()
+	defer d.opMutex.Unlock()
+	d.inverted = inverted
+}
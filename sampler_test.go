@@ -0,0 +1,175 @@
+package hx711
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+// cannedSampler returns the loaded conversions and errors in order.
+type cannedSampler struct {
+	values []uint32
+	errs   []error
+	idx    int
+}
+
+func (c *cannedSampler) ReadConversion() (uint32, error) {
+	v, err := c.values[c.idx], c.errs[c.idx]
+	c.idx++
+	return v, err
+}
+
+func TestDevice_SetSampler(t *testing.T) {
+	failure := errors.New("bus error")
+	s := &cannedSampler{
+		values: []uint32{1100, 1100, 0, 1150},
+		errs:   []error{nil, nil, failure, nil},
+	}
+	dtp := &counterDataPin{}
+	td := Device{
+		sck:               dtp,
+		dt:                dtp,
+		initialized:       true,
+		gain:              Gain128,
+		smoothingFactor:   2,
+		calibrationFactor: 2,
+		offset:            100,
+	}
+	td.SetSampler(s)
+	if v := td.ReadCalibrated(); v != 2000 {
+		t.Logf("expected %d but got %d", 2000, v)
+		t.FailNow()
+	}
+	// the failed conversion is replaced by the last good one
+	if v := td.Read(); v != 1025 {
+		t.Logf("expected %d but got %d", 1025, v)
+		t.FailNow()
+	}
+	if diag := td.Diagnostics(); diag.FailedConversions != 1 || !errors.Is(diag.LastError, failure) {
+		t.Logf("expected a failed conversion with %v but got %d and %v", failure, diag.FailedConversions, diag.LastError)
+		t.FailNow()
+	}
+	if dtp.countH != 0 || dtp.getIdx != 0 {
+		t.Logf("expected the pins to not be used but tick was called %d times and DT sampled %d", dtp.countH, dtp.getIdx)
+		t.FailNow()
+	}
+}
+
+// cancelingSampler cancels its context after returning <after> conversions.
+type cancelingSampler struct {
+	value  uint32
+	after  int
+	cancel context.CancelFunc
+	calls  int
+}
+
+func (c *cancelingSampler) ReadConversion() (uint32, error) {
+	c.calls++
+	if c.calls == c.after {
+		c.cancel()
+	}
+	return c.value, nil
+}
+
+func TestDevice_SetMinSamples(t *testing.T) {
+	failure := errors.New("bus error")
+	newDevice := func(s Sampler) *Device {
+		td := &Device{
+			initialized:     true,
+			gain:            Gain128,
+			smoothingFactor: 4,
+			sampler:         s,
+		}
+		td.SetMinSamples(2)
+		return td
+	}
+
+	td := newDevice(&cannedSampler{
+		values: []uint32{1000, 0, 1050, 0},
+		errs:   []error{nil, failure, nil, failure},
+	})
+	// the failures are left out instead of repeating 1000 and 1050
+	if v := td.Read(); v != 1025 {
+		t.Logf("expected %d but got %d", 1025, v)
+		t.FailNow()
+	}
+	if diag := td.Diagnostics(); diag.Conversions != 2 || diag.FailedConversions != 2 {
+		t.Logf("expected 2 conversions averaged and 2 failed but got %d and %d", diag.Conversions, diag.FailedConversions)
+		t.FailNow()
+	}
+
+	td = newDevice(&cannedSampler{
+		values: []uint32{0, 0, 1200, 0, 0, 0, 0, 0},
+		errs:   []error{failure, failure, nil, failure, failure, failure, failure, failure},
+	})
+	if v := td.Read(); v != 1200 || !errors.Is(td.Diagnostics().LastError, ErrTooFewSamples) {
+		t.Logf("expected %d and ErrTooFewSamples but got %d and %v", 1200, v, td.Diagnostics().LastError)
+		t.FailNow()
+	}
+	if _, err := td.ReadContext(context.Background()); !errors.Is(err, ErrTooFewSamples) {
+		t.Logf("expected ErrTooFewSamples but got %v", err)
+		t.FailNow()
+	}
+
+	// the read times out after 2 conversions
+	ctx, cancel := context.WithCancel(context.Background())
+	s := &cancelingSampler{value: 1000, after: 2, cancel: cancel}
+	td = newDevice(s)
+	if v, err := td.ReadContext(ctx); err != nil || v != 1000 {
+		t.Logf("expected %d but got %d and %v", 1000, v, err)
+		t.FailNow()
+	}
+	if s.calls != 2 || td.Diagnostics().Conversions != 2 {
+		t.Logf("expected 2 conversions but the sampler was called %d times and %d were averaged", s.calls, td.Diagnostics().Conversions)
+		t.FailNow()
+	}
+
+	// without a minimum the whole read fails
+	ctx, cancel = context.WithCancel(context.Background())
+	td = newDevice(&cancelingSampler{value: 1000, after: 2, cancel: cancel})
+	td.SetMinSamples(0)
+	if _, err := td.ReadContext(ctx); !errors.Is(err, context.Canceled) {
+		t.Logf("expected context.Canceled but got %v", err)
+		t.FailNow()
+	}
+}
+
+func Test_invert(t *testing.T) {
+	tests := []struct {
+		value uint32
+		want  int64
+	}{
+		{value: 1000, want: -1000},
+		{value: 0xFFFC18, want: 1000},
+		{value: 0, want: 0},
+		{value: saturatedHigh, want: -int64(saturatedHigh)},
+		{value: saturatedLow, want: int64(saturatedHigh)},
+	}
+	for _, tt := range tests {
+		if got := toInt64(invert(tt.value)); got != tt.want {
+			t.Logf("inverting %d expected %d but got %d", toInt64(tt.value), tt.want, got)
+			t.FailNow()
+		}
+	}
+}
+
+func TestDevice_SetInverted(t *testing.T) {
+	dtp := &counterDataPin{}
+	dtp.loadBits([]uint32{50000, 50000, 50000, 50000}, false)
+	td := Device{
+		sck:             dtp,
+		dt:              dtp,
+		initialized:     true,
+		gain:            Gain128,
+		smoothingFactor: 2,
+	}
+	if v := td.Read(); v != 50000 {
+		t.Logf("expected %d but got %d", 50000, v)
+		t.FailNow()
+	}
+	td.SetInverted(true)
+	if v := td.Read(); v != -50000 {
+		t.Logf("expected inverted %d but got %d", -50000, v)
+		t.FailNow()
+	}
+}
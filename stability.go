@@ -0,0 +1,83 @@
+package hx711
+
+// StabilityState tells if the readings of a Device have settled, see SetStabilityHysteresis.
+type StabilityState int
+
+const (
+	Unstable StabilityState = iota // readings are still moving
+	Stable                         // readings settled
+)
+
+func (s StabilityState) String() string {
+	if s == Stable {
+		return "stable"
+	}
+	return "unstable"
+}
+
+// stability is a state machine with hysteresis fed by each read.
+type stability struct {
+	// samples is the amount of consecutive reads within enterTolerance needed to become stable, 0 is disabled.
+	samples        int
+	enterTolerance int64
+	exitTolerance  int64
+	onChange       func(StabilityState)
+
+	state     StabilityState
+	reference int64
+	run       int
+}
+
+// update feeds a reading to the state machine.
+func (s *stability) update(v int64) {
+	if s.samples == 0 {
+		return
+	}
+	if s.state == Stable {
+		if abs64(v-s.reference) > s.exitTolerance {
+			s.state = Unstable
+			s.reference, s.run = v, 1
+			if s.onChange != nil {
+				s.onChange(Unstable)
+			}
+		}
+		return
+	}
+	if s.run == 0 || abs64(v-s.reference) > s.enterTolerance {
+		s.reference, s.run = v, 1
+	} else {
+		s.run++
+	}
+	if s.run >= s.samples {
+		s.state = Stable
+		if s.onChange != nil {
+			s.onChange(Stable)
+		}
+	}
+}
+
+// SetStabilityHysteresis enables tracking of the stability of reads: they become Stable after <samples>
+// consecutive reads within enterTolerance (raw units) of the first of them and only become Unstable again when a
+// read is further than exitTolerance from it, which should be larger than enterTolerance so the state does not
+// chatter. onChange, if not nil, is called on each transition, while the device is locked so it must not call
+// the device. Pass 0 samples to disable it.
+func (d *Device) SetStabilityHysteresis(enterTolerance, exitTolerance int64, samples int, onChange func(StabilityState)) {
+	d.opMutex.Lock()
+	defer d.opMutex.Unlock()
+	if samples < 0 {
+		samples = 0
+	}
+	d.stability = stability{
+		samples:        samples,
+		enterTolerance: enterTolerance,
+		exitTolerance:  exitTolerance,
+		onChange:       onChange,
+	}
+}
+
+// StabilityState returns the stability of the reads so far, see SetStabilityHysteresis.
+func (d *Device) StabilityState() StabilityState {
+	d.opMutex.Lock()
+	defer d.opMutex.Unlock()
+	return d.stability.state
+}
@@ -0,0 +1,42 @@
+package hx711
+
+import "testing"
+
+func TestDevice_StabilityState(t *testing.T) {
+	dtp := &counterDataPin{}
+	values := []uint32{
+		1000, 1200, 1203, 1201, // settles after 3 reads within 5 of 1200
+		1208, 1190, // moves within the exit tolerance, stays stable
+		1300, 1302, 1304, // moves past the exit tolerance and settles again
+		1301,
+	}
+	states := []StabilityState{
+		Unstable, Unstable, Unstable, Stable,
+		Stable, Stable,
+		Unstable, Unstable, Stable,
+		Stable,
+	}
+	dtp.loadBits(values, false)
+	td := Device{
+		sck:             dtp,
+		dt:              dtp,
+		initialized:     true,
+		gain:            Gain128,
+		smoothingFactor: 1,
+	}
+	var transitions []StabilityState
+	td.SetStabilityHysteresis(5, 20, 3, func(s StabilityState) {
+		transitions = append(transitions, s)
+	})
+	for i, want := range states {
+		td.Read()
+		if got := td.StabilityState(); got != want {
+			t.Logf("after reading %d expected %s but got %s", values[i], want, got)
+			t.FailNow()
+		}
+	}
+	if len(transitions) != 3 || transitions[0] != Stable || transitions[1] != Unstable || transitions[2] != Stable {
+		t.Logf("expected transitions to stable, unstable and stable but got %v", transitions)
+		t.FailNow()
+	}
+}
@@ -0,0 +1,116 @@
+package hx711
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+)
+
+func TestNewPreset(t *testing.T) {
+	td := NewPreset(CalibrationState{Offset: 100, Tare: 20, CalibrationFactor: 2})
+	if td.offset != 100 || td.tare != 20 || td.GetCalibrationFactor() != 2 {
+		t.Logf("preset not applied, got offset %d, tare %d and factor %f", td.offset, td.tare, td.GetCalibrationFactor())
+		t.FailNow()
+	}
+	if d := td.EstimatedReadDuration(); d != 0 {
+		t.Logf("expected reads not to hold the clock but they take %s", d)
+		t.FailNow()
+	}
+	// stub pins always read 0
+	if v := td.Read(); v != -120 {
+		t.Logf("expected %d but got %d", -120, v)
+		t.FailNow()
+	}
+	if v := td.ReadCalibrated(); v != -240 {
+		t.Logf("expected %d but got %d", -240, v)
+		t.FailNow()
+	}
+
+	dtp := &counterDataPin{}
+	dtp.loadBits([]uint32{1120, 1120}, false)
+	td = NewPreset(CalibrationState{Offset: 100, Tare: 20, CalibrationFactor: 2})
+	td.sck, td.dt, td.smoothingFactor = dtp, dtp, 2
+	if v := td.ReadCalibrated(); v != 2000 {
+		t.Logf("expected %d but got %d", 2000, v)
+		t.FailNow()
+	}
+}
+
+func TestNewPreset_notCalibrated(t *testing.T) {
+	td := NewPreset(CalibrationState{Offset: 100})
+	if td.IsCalibrated() || td.GetCalibrationFactor() != 1 {
+		t.Logf("expected an uncalibrated device with factor 1 but got %v and %f", td.IsCalibrated(), td.GetCalibrationFactor())
+		t.FailNow()
+	}
+	td = NewPreset(CalibrationState{CalibrationFactor: 3})
+	if !td.IsCalibrated() {
+		t.Log("expected a preset with a factor to be calibrated")
+		t.FailNow()
+	}
+}
+
+func TestDevice_SaveState(t *testing.T) {
+	td := NewPreset(CalibrationState{Offset: -8388000, Tare: 20, CalibrationFactor: 0.0123})
+	var buf bytes.Buffer
+	if err := td.SaveState(&buf); err != nil {
+		t.Logf("unexpected error saving: %v", err)
+		t.FailNow()
+	}
+	if buf.Len() != stateSize {
+		t.Logf("expected %d bytes but got %d", stateSize, buf.Len())
+		t.FailNow()
+	}
+	saved := append([]byte(nil), buf.Bytes()...)
+
+	restored := NewPreset(CalibrationState{})
+	if err := restored.LoadState(&buf); err != nil {
+		t.Logf("unexpected error loading: %v", err)
+		t.FailNow()
+	}
+	if restored.offset != -8388000 || restored.tare != 20 || restored.GetCalibrationFactor() != 0.0123 || !restored.IsCalibrated() {
+		t.Logf("state not restored, got offset %d, tare %d and factor %f", restored.offset, restored.tare, restored.GetCalibrationFactor())
+		t.FailNow()
+	}
+
+	// an uncalibrated state drops the calibration of the device it is loaded on
+	buf.Reset()
+	if err := NewPreset(CalibrationState{Offset: 100}).SaveState(&buf); err != nil {
+		t.Logf("unexpected error saving: %v", err)
+		t.FailNow()
+	}
+	if err := restored.LoadState(&buf); err != nil {
+		t.Logf("unexpected error loading: %v", err)
+		t.FailNow()
+	}
+	if restored.offset != 100 || restored.IsCalibrated() || restored.GetCalibrationFactor() != 1 {
+		t.Logf("expected offset 100 and no calibration, got %d, %v and factor %f", restored.offset, restored.IsCalibrated(), restored.GetCalibrationFactor())
+		t.FailNow()
+	}
+
+	saved[0] = stateVersion + 1
+	if err := restored.LoadState(bytes.NewReader(saved)); !errors.Is(err, ErrBadState) {
+		t.Logf("expected ErrBadState for an unknown version but got %v", err)
+		t.FailNow()
+	}
+	if err := restored.LoadState(bytes.NewReader(saved[:10])); err == nil {
+		t.Log("expected an error for a short state")
+		t.FailNow()
+	}
+}
+
+func TestDevice_SaveState_notCalibrated(t *testing.T) {
+	var buf bytes.Buffer
+	if err := NewPreset(CalibrationState{Offset: 100}).SaveState(&buf); err != nil {
+		t.Logf("unexpected error saving: %v", err)
+		t.FailNow()
+	}
+	restored := NewPreset(CalibrationState{})
+	if err := restored.LoadState(&buf); err != nil {
+		t.Logf("unexpected error loading: %v", err)
+		t.FailNow()
+	}
+	if restored.offset != 100 || restored.IsCalibrated() {
+		t.Logf("expected offset 100 and no calibration, got %d and %v", restored.offset, restored.IsCalibrated())
+		t.FailNow()
+	}
+}
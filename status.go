@@ -0,0 +1,48 @@
+package hx711
+
+import "math/bits"
+
+// SetStatusBits is for clones that use extra clock cycles after the 24 data bits to send status or parity,
+// n bits are read after the data and before the gain pulses and are available through LastStatusBits.
+// check, if not nil, is called with the data and status bits of every read, a false return is counted
+// in Diagnostics().StatusCheckFailures, EvenParity is provided for the common case.
+// Do not use this with a canonical hx711, it takes those extra pulses as gain selection.
+func (d *Device) SetStatusBits(n int, check func(data, status uint32) bool) {
+	d.opMutex.Lock()
+	defer d.opMutex.Unlock()
+	if n < 0 {
+		n = 0
+	}
+	d.statusBits = n
+	d.statusCheck = check
+	d.lastStatus = 0
+}
+
+// LastStatusBits returns the status bits of the most recent read, see SetStatusBits.
+func (d *Device) LastStatusBits() uint32 {
+	d.opMutex.Lock()
+	defer d.opMutex.Unlock()
+	return d.lastStatus
+}
+
+// readStatus reads the status bits following data.
+func (d *Device) readStatus(data uint32) {
+	var status uint32
+	for i := 0; i < d.statusBits; i++ {
+		d.tick()
+		status = status << 1
+		if d.getBit() {
+			status = status | 1
+		}
+	}
+	d.lastStatus = status
+	if d.statusCheck != nil && !d.statusCheck(data, status) {
+		d.diag.StatusCheckFailures++
+	}
+}
+
+// EvenParity is a status check for SetStatusBits with a single status bit that makes the amount of 1s
+// in data plus status even.
+func EvenParity(data, status uint32) bool {
+	return (bits.OnesCount32(data&0xFFFFFF)+bits.OnesCount32(status&1))%2 == 0
+}
@@ -0,0 +1,67 @@
+package hx711
+
+import "testing"
+
+func TestDevice_SetStatusBits(t *testing.T) {
+	dtp := &counterDataPin{}
+	dtp.loadBits([]uint32{0b101}, false)
+	// parity bit making the ones even
+	dtp.get = append(dtp.get, false)
+	dtp.loadBits([]uint32{0b111}, false)
+	// wrong parity bit
+	dtp.get = append(dtp.get, false)
+	td := Device{
+		sck:         dtp,
+		dt:          dtp,
+		initialized: true,
+		gain:        Gain128,
+	}
+	td.SetStatusBits(1, EvenParity)
+
+	if v := td.read(); v != 0b101 {
+		t.Logf("expected %b but got %b", 0b101, v)
+		t.FailNow()
+	}
+	if s := td.LastStatusBits(); s != 0 {
+		t.Logf("expected status %b but got %b", 0, s)
+		t.FailNow()
+	}
+	if f := td.Diagnostics().StatusCheckFailures; f != 0 {
+		t.Logf("expected no status check failures but got %d", f)
+		t.FailNow()
+	}
+	if v := td.read(); v != 0b111 {
+		t.Logf("expected %b but got %b", 0b111, v)
+		t.FailNow()
+	}
+	if f := td.Diagnostics().StatusCheckFailures; f != 1 {
+		t.Logf("expected a status check failure but got %d", f)
+		t.FailNow()
+	}
+	want := 2 * (24 + 1 + int(Gain128))
+	if dtp.countL != dtp.countH || dtp.countL != want {
+		t.Logf("expected %d ticks but tick was called %d times for High and %d times for Low", want, dtp.countH, dtp.countL)
+		t.FailNow()
+	}
+}
+
+func TestDevice_SetStatusBits_trailingBits(t *testing.T) {
+	dtp := &counterDataPin{}
+	dtp.loadBits([]uint32{50000}, false)
+	dtp.get = append(dtp.get, true, false, true)
+	td := Device{
+		sck:         dtp,
+		dt:          dtp,
+		initialized: true,
+		gain:        Gain64,
+	}
+	td.SetStatusBits(3, nil)
+	if v := td.read(); v != 50000 {
+		t.Logf("expected %d but got %d", 50000, v)
+		t.FailNow()
+	}
+	if s := td.LastStatusBits(); s != 0b101 {
+		t.Logf("expected status %b but got %b", 0b101, s)
+		t.FailNow()
+	}
+}
@@ -0,0 +1,126 @@
+package hx711
+
+import (
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Stream reads a Device in the background and hands each value to its subscribers.
+type Stream struct {
+	d        *Device
+	interval time.Duration
+
+	mu          sync.Mutex
+	subscribers []chan int64
+	paused      bool
+
+	// latest is the last value read, see LatestValue
+	latest atomic.Int64
+
+	stop chan struct{}
+	done chan struct{}
+}
+
+// Stream starts reading the device every interval in the background, values are the same Read returns.
+// Remember to Stop it, reads from other goroutines are still possible but will contend for the device.
+func (d *Device) Stream(interval time.Duration) *Stream {
+	s := &Stream{
+		d:        d,
+		interval: interval,
+		stop:     make(chan struct{}),
+		done:     make(chan struct{}),
+	}
+	go s.run()
+	return s
+}
+
+func (s *Stream) run() {
+	defer close(s.done)
+	for {
+		select {
+		case <-s.stop:
+			return
+		case <-s.d.clock().After(s.interval):
+		}
+		if s.Paused() {
+			continue
+		}
+		v := s.d.Read()
+		s.latest.Store(v)
+		s.mu.Lock()
+		// we might have been paused while reading
+		if !s.paused {
+			for _, sub := range s.subscribers {
+				// a slow subscriber loses values rather than stalling everyone
+				select {
+				case sub <- v:
+				default:
+				}
+			}
+		}
+		s.mu.Unlock()
+	}
+}
+
+// Subscribe returns a channel that receives the values read, buffer is the amount of values that
+// can queue up before new ones are dropped for this subscriber.
+func (s *Stream) Subscribe(buffer int) <-chan int64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	sub := make(chan int64, buffer)
+	s.subscribers = append(s.subscribers, sub)
+	return sub
+}
+
+// Unsubscribe stops sending values to and closes a channel returned by Subscribe.
+func (s *Stream) Unsubscribe(sub <-chan int64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for i, candidate := range s.subscribers {
+		if candidate == sub {
+			close(candidate)
+			s.subscribers = append(s.subscribers[:i], s.subscribers[i+1:]...)
+			return
+		}
+	}
+}
+
+// LatestValue returns the most recent value read by the stream, 0 before the first one. It never blocks and
+// there is nothing to drain, for readers that only care about the newest value, ie: a display.
+func (s *Stream) LatestValue() int64 {
+	return s.latest.Load()
+}
+
+// Pause stops reading the device, ie: while a motor causes vibration, subscribers are kept.
+func (s *Stream) Pause() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.paused = true
+}
+
+// Resume starts reading again after a Pause.
+func (s *Stream) Resume() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.paused = false
+}
+
+// Paused reports whether the stream is paused.
+func (s *Stream) Paused() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.paused
+}
+
+// Stop stops reading the device and closes all the subscriber channels, the stream can't be used after.
+func (s *Stream) Stop() {
+	close(s.stop)
+	<-s.done
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, sub := range s.subscribers {
+		close(sub)
+	}
+	s.subscribers = nil
+}
@@ -0,0 +1,143 @@
+package hx711
+
+import (
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestStream_PauseResume(t *testing.T) {
+	dtp := &counterDataPin{loop: true}
+	dtp.loadBits([]uint32{50000}, false)
+	td := &Device{
+		sck:             dtp,
+		dt:              dtp,
+		initialized:     true,
+		gain:            Gain128,
+		smoothingFactor: 1,
+	}
+	s := td.Stream(time.Millisecond)
+	defer s.Stop()
+	sub := s.Subscribe(1)
+
+	select {
+	case v := <-sub:
+		if v != 50000 {
+			t.Logf("expected %d but got %d", 50000, v)
+			t.FailNow()
+		}
+	case <-time.After(time.Second):
+		t.Log("timed out waiting for a value")
+		t.FailNow()
+	}
+
+	s.Pause()
+	// a value might have been queued before the pause
+	select {
+	case <-sub:
+	default:
+	}
+	select {
+	case v := <-sub:
+		t.Logf("expected no values while paused but got %d", v)
+		t.FailNow()
+	case <-time.After(20 * time.Millisecond):
+	}
+	if !s.Paused() {
+		t.Log("expected stream to be paused")
+		t.FailNow()
+	}
+
+	s.Resume()
+	select {
+	case <-sub:
+	case <-time.After(time.Second):
+		t.Log("timed out waiting for a value after resuming")
+		t.FailNow()
+	}
+}
+
+func TestStream_Unsubscribe(t *testing.T) {
+	dtp := &counterDataPin{loop: true}
+	dtp.loadBits([]uint32{50000}, false)
+	td := &Device{
+		sck:             dtp,
+		dt:              dtp,
+		initialized:     true,
+		gain:            Gain128,
+		smoothingFactor: 1,
+	}
+	s := td.Stream(time.Millisecond)
+	defer s.Stop()
+	sub := s.Subscribe(0)
+	s.Unsubscribe(sub)
+	if _, ok := <-sub; ok {
+		t.Log("expected channel to be closed after unsubscribing")
+		t.FailNow()
+	}
+}
+
+// countingSampler returns 1, 2, 3... as conversions.
+type countingSampler struct {
+	n atomic.Uint32
+}
+
+func (c *countingSampler) ReadConversion() (uint32, error) {
+	return c.n.Add(1), nil
+}
+
+func TestStream_LatestValue(t *testing.T) {
+	sampler := &countingSampler{}
+	td := &Device{
+		initialized:     true,
+		gain:            Gain128,
+		smoothingFactor: 1,
+		sampler:         sampler,
+	}
+	s := td.Stream(time.Microsecond)
+
+	var wg sync.WaitGroup
+	stop := make(chan struct{})
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			var last int64
+			for {
+				select {
+				case <-stop:
+					return
+				default:
+				}
+				v := s.LatestValue()
+				if v < last {
+					t.Errorf("expected values to only move forward but got %d after %d", v, last)
+					return
+				}
+				last = v
+			}
+		}()
+	}
+	// readers hammering the value must not hold the stream back
+	deadline := time.After(5 * time.Second)
+	for s.LatestValue() < 100 {
+		select {
+		case <-deadline:
+			close(stop)
+			wg.Wait()
+			s.Stop()
+			t.Logf("expected the stream to keep reading but the latest value is %d", s.LatestValue())
+			t.FailNow()
+		default:
+		}
+		time.Sleep(time.Millisecond)
+	}
+	close(stop)
+	wg.Wait()
+	s.Stop()
+	if v, read := s.LatestValue(), int64(sampler.n.Load()); v != read {
+		t.Logf("expected the latest value to be the last read, %d, but got %d", read, v)
+		t.FailNow()
+	}
+}
@@ -0,0 +1,56 @@
+package hx711
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+// stretchPin holds SCK high for stretch on its at-th pulse by moving the clock forward.
+type stretchPin struct {
+	*counterDataPin
+	clk     *fakeClock
+	at      int
+	stretch time.Duration
+}
+
+func (s *stretchPin) High() {
+	s.counterDataPin.High()
+	if s.countH == s.at {
+		s.clk.Advance(s.stretch)
+	}
+}
+
+func TestDevice_SetMaxPulseWidth(t *testing.T) {
+	dtp := &counterDataPin{}
+	dtp.loadBits([]uint32{1100, 2000, 1100}, false)
+	clk := newFakeClock()
+	td := Device{
+		sck:             &stretchPin{counterDataPin: dtp, clk: clk, at: 24 + 12, stretch: 80 * time.Microsecond},
+		dt:              dtp,
+		initialized:     true,
+		gain:            Gain128,
+		smoothingFactor: 1,
+		offset:          100,
+		clk:             clk,
+	}
+	td.SetMaxPulseWidth(50 * time.Microsecond)
+	if v, err := td.ReadContext(context.Background()); err != nil || v != 1000 {
+		t.Logf("expected %d but got %d and %v", 1000, v, err)
+		t.FailNow()
+	}
+	// the second conversion gets a stretched pulse and is dropped
+	if _, err := td.ReadContext(context.Background()); !errors.Is(err, ErrClockStretched) {
+		t.Logf("expected %v but got %v", ErrClockStretched, err)
+		t.FailNow()
+	}
+	if diag := td.Diagnostics(); diag.FailedConversions != 1 || !errors.Is(diag.LastError, ErrClockStretched) {
+		t.Logf("expected the stretched conversion in diagnostics but got %+v", diag)
+		t.FailNow()
+	}
+	if v, err := td.ReadContext(context.Background()); err != nil || v != 1000 {
+		t.Logf("expected %d after the stretched read but got %d and %v", 1000, v, err)
+		t.FailNow()
+	}
+}
@@ -0,0 +1,61 @@
+package hx711
+
+// TraceKind identifies the step of the bit-bang sequence a TraceEvent describes.
+type TraceKind int
+
+const (
+	TraceTick  TraceKind = iota // a full clock pulse (high then low) on SCK
+	TraceBit                    // a bit sampled from DT, see TraceEvent.Bit
+	TraceValue                  // the 24 bit value decoded from the bits of a conversion, see TraceEvent.Value
+)
+
+// String returns a human friendly name for the kind, handy when logging traces.
+func (k TraceKind) String() string {
+	switch k {
+	case TraceTick:
+		return "tick"
+	case TraceBit:
+		return "bit"
+	case TraceValue:
+		return "value"
+	}
+	return "unknown"
+}
+
+// TraceEvent is a single step of the conversation with the chip.
+type TraceEvent struct {
+	Kind TraceKind
+	// Bit holds the sampled DT level for TraceBit events.
+	Bit bool
+	// Value holds the raw, not sign extended, conversion for TraceValue events.
+	Value uint32
+}
+
+// SetTracer sets a function that will be called for each tick, each bit read and each decoded value,
+// this is meant to be compared against a logic analyzer when a board does not behave.
+// The tracer is called while bit banging, so it should be fast or it will alter the timing,
+// pass nil to disable it (the default).
+func (d *Device) SetTracer(fn func(event TraceEvent)) {
+	d.opMutex.Lock()
+	defer d.opMutex.Unlock()
+	d.tracer = fn
+}
+
+// SetCaptureBits makes the device keep every DT sample of the most recent conversion, status bits included,
+// see LastBits. It is off by default so reads don't pay for it.
+func (d *Device) SetCaptureBits(enable bool) {
+	d.opMutex.Lock()
+	defer d.opMutex.Unlock()
+	d.captureBits = enable
+	if enable && d.lastBits == nil {
+		d.lastBits = make([]bool, 0, 24)
+	}
+}
+
+// LastBits returns the DT samples, most significant first, of the most recent conversion, empty unless
+// SetCaptureBits was enabled. Bits not sampled, ie: by ReadCoarse, are not there.
+func (d *Device) LastBits() []bool {
+	d.opMutex.Lock()
+	defer d.opMutex.Unlock()
+	return append([]bool(nil), d.lastBits...)
+}
@@ -0,0 +1,88 @@
+package hx711
+
+import "testing"
+
+func TestDevice_SetTracer(t *testing.T) {
+	for _, g := range []gainLVL{Gain128, Gain64, Gain32} {
+		dtp := &counterDataPin{}
+		dtp.loadBits([]uint32{50000}, false)
+		td := Device{
+			sck:         dtp,
+			dt:          dtp,
+			initialized: true,
+			gain:        g,
+		}
+		counts := map[TraceKind]int{}
+		var value uint32
+		var bits uint32
+		td.SetTracer(func(event TraceEvent) {
+			counts[event.Kind]++
+			switch event.Kind {
+			case TraceBit:
+				bits = bits << 1
+				if event.Bit {
+					bits = bits | 1
+				}
+			case TraceValue:
+				value = event.Value
+			}
+		})
+		v := td.read()
+		if counts[TraceTick] != 24+int(g) || counts[TraceBit] != 24 || counts[TraceValue] != 1 {
+			t.Logf("Gain is %d but got %d ticks, %d bits and %d values traced",
+				g, counts[TraceTick], counts[TraceBit], counts[TraceValue])
+			t.FailNow()
+		}
+		if value != v || bits != v {
+			t.Logf("read returned %d but trace reported value %d and bits %d", v, value, bits)
+			t.FailNow()
+		}
+	}
+}
+
+func TestDevice_SetTracer_nil(t *testing.T) {
+	dtp := &counterDataPin{}
+	dtp.loadBits([]uint32{50000}, false)
+	td := Device{
+		sck:         dtp,
+		dt:          dtp,
+		initialized: true,
+		gain:        Gain128,
+	}
+	td.SetTracer(nil)
+	if v := td.read(); v != 50000 {
+		t.Logf("expected %d but got %d", 50000, v)
+		t.FailNow()
+	}
+}
+
+func TestDevice_LastBits(t *testing.T) {
+	dtp := &counterDataPin{}
+	pattern := uint32(0b101100111000111100001111)
+	dtp.loadBits([]uint32{1000, pattern}, false)
+	td := Device{
+		sck:             dtp,
+		dt:              dtp,
+		gain:            Gain128,
+		smoothingFactor: 1,
+		initialized:     true,
+	}
+	td.Read()
+	if bits := td.LastBits(); len(bits) != 0 {
+		t.Logf("expected no bits without capture but got %d", len(bits))
+		t.FailNow()
+	}
+	td.SetCaptureBits(true)
+	td.Read()
+	bits := td.LastBits()
+	if len(bits) != 24 {
+		t.Logf("expected %d bits but got %d", 24, len(bits))
+		t.FailNow()
+	}
+	for i, b := range bits {
+		if want := pattern&(1<<(23-i)) != 0; b != want {
+			t.Logf("expected bit %d to be %v but got %v", i, want, b)
+			t.FailNow()
+		}
+	}
+}
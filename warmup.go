@@ -0,0 +1,35 @@
+package hx711
+
+// warmup refines offset with the reads done while the cell warms up, see WithWarmup.
+type warmup struct {
+	active bool
+	sum    int64
+	count  int64
+}
+
+// WithWarmup keeps refining the baseline offset after initialization with every read, as the average of the
+// baseline and all reads, until FinalizeWarmup is called. The cell drifts while it thermally settles after a
+// cold start, so this gives a better offset for precision uses, the scale must stay empty until finalized.
+func WithWarmup() Option {
+	return func(d *Device) {
+		d.warmup.active = true
+	}
+}
+
+// add takes raw into the warm-up average and updates offset with it.
+func (w *warmup) add(d *Device, raw int64) {
+	if !w.active {
+		return
+	}
+	w.sum += raw
+	w.count++
+	d.offset = w.sum / w.count
+}
+
+// FinalizeWarmup stops refining offset with reads, see WithWarmup, and returns the offset it settled on.
+func (d *Device) FinalizeWarmup() int64 {
+	d.opMutex.Lock()
+	defer d.opMutex.Unlock()
+	d.warmup = warmup{}
+	return d.offset
+}
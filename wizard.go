@@ -0,0 +1,105 @@
+package hx711
+
+import (
+	"fmt"
+	"strconv"
+)
+
+// CalibrationStep is the step a CalibrationSession is waiting on.
+type CalibrationStep int
+
+const (
+	// StepRemoveWeight waits for the scale to be emptied so it can be zeroed.
+	StepRemoveWeight CalibrationStep = iota
+	// StepPlaceWeight waits for the known weight to be placed on the scale.
+	StepPlaceWeight
+	// StepDone means the calibration factor was set.
+	StepDone
+)
+
+// CalibrationSession guides a calibration through zeroing the empty scale and then weighing a known weight,
+// in that order, see StartCalibration.
+type CalibrationSession struct {
+	d      *Device
+	weight float64
+	prompt func(step CalibrationStep, weight float64)
+	step   CalibrationStep
+	// unit is the unit of the weight for the prompts, see NextPrompt
+	unit string
+}
+
+// StartCalibration starts a guided calibration against knownWeight, prompt is called with each step the user
+// needs to perform, starting with StepRemoveWeight right away, call Step once the user did it.
+func (d *Device) StartCalibration(knownWeight float64, prompt func(step CalibrationStep, weight float64)) (*CalibrationSession, error) {
+	if knownWeight == 0 {
+		return nil, fmt.Errorf("starting calibration: %w", ErrZeroWeight)
+	}
+	s := &CalibrationSession{d: d, weight: knownWeight, prompt: prompt}
+	s.enter(StepRemoveWeight)
+	return s, nil
+}
+
+// enter moves the session to step and prompts for it.
+func (s *CalibrationSession) enter(step CalibrationStep) {
+	s.step = step
+	if s.prompt != nil {
+		s.prompt(step, s.weight)
+	}
+}
+
+// Step performs the reads for the current step, which the user should have completed, and moves to the next
+// one, it reports whether the calibration is done. If calibrating fails the session stays in StepPlaceWeight
+// so the step can be retried.
+func (s *CalibrationSession) Step() (done bool, err error) {
+	switch s.step {
+	case StepRemoveWeight:
+		s.d.Zero()
+		s.enter(StepPlaceWeight)
+	case StepPlaceWeight:
+		if _, err := s.d.Calibrate(s.weight); err != nil {
+			return false, err
+		}
+		s.enter(StepDone)
+	}
+	return s.step == StepDone, nil
+}
+
+// CurrentStep returns the step the session is waiting on.
+func (s *CalibrationSession) CurrentStep() CalibrationStep {
+	return s.step
+}
+
+// CalibrateInteractive starts a guided calibration against knownWeight that is driven by text, for headless
+// devices where the prompts are relayed over serial or a network: show NextPrompt to the user and call Advance
+// once they did what it says. The weight in the prompts is followed by the unit set with SetUnit.
+func (d *Device) CalibrateInteractive(knownWeight float64) (*CalibrationSession, error) {
+	s, err := d.StartCalibration(knownWeight, nil)
+	if err != nil {
+		return nil, err
+	}
+	d.opMutex.Lock()
+	s.unit = d.unit
+	d.opMutex.Unlock()
+	return s, nil
+}
+
+// NextPrompt returns what the user needs to do for the current step, in english.
+func (s *CalibrationSession) NextPrompt() string {
+	switch s.step {
+	case StepRemoveWeight:
+		return "Remove all weight and press enter"
+	case StepPlaceWeight:
+		weight := strconv.FormatFloat(s.weight, 'f', -1, 64)
+		if s.unit != "" {
+			weight += " " + s.unit
+		}
+		return "Place " + weight + " and press enter"
+	}
+	return "Calibration done"
+}
+
+// Advance is Step for when the prompt is all that matters, see NextPrompt and CurrentStep.
+func (s *CalibrationSession) Advance() error {
+	_, err := s.Step()
+	return err
+}
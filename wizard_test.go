@@ -0,0 +1,131 @@
+package hx711
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestDevice_StartCalibration(t *testing.T) {
+	dtp := &counterDataPin{}
+	// empty scale, then 500g on it
+	dtp.loadBits([]uint32{1000, 1000, 3000, 3000}, false)
+	td := &Device{
+		sck:               dtp,
+		dt:                dtp,
+		initialized:       true,
+		gain:              Gain128,
+		smoothingFactor:   2,
+		calibrationFactor: 1,
+	}
+	if _, err := td.StartCalibration(0, nil); !errors.Is(err, ErrZeroWeight) {
+		t.Logf("expected %v but got %v", ErrZeroWeight, err)
+		t.FailNow()
+	}
+	var prompts []CalibrationStep
+	s, err := td.StartCalibration(500, func(step CalibrationStep, weight float64) {
+		if weight != 500 {
+			t.Errorf("expected to be prompted with 500 but got %f", weight)
+		}
+		prompts = append(prompts, step)
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 2; i++ {
+		done, err := s.Step()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if done != (i == 1) {
+			t.Logf("step %d expected done to be %v", i, i == 1)
+			t.FailNow()
+		}
+	}
+	want := []CalibrationStep{StepRemoveWeight, StepPlaceWeight, StepDone}
+	if len(prompts) != len(want) || prompts[0] != want[0] || prompts[1] != want[1] || prompts[2] != want[2] {
+		t.Logf("expected prompts %v but got %v", want, prompts)
+		t.FailNow()
+	}
+	if td.offset != 1000 || td.GetCalibrationFactor() != 0.25 {
+		t.Logf("expected offset %d and factor %f but got %d and %f", 1000, 0.25, td.offset, td.GetCalibrationFactor())
+		t.FailNow()
+	}
+	if done, err := s.Step(); !done || err != nil || dtp.getIdx != len(dtp.get) {
+		t.Log("expected stepping a finished session to do nothing")
+		t.FailNow()
+	}
+}
+
+func TestCalibrationSession_retry(t *testing.T) {
+	dtp := &counterDataPin{}
+	// empty scale, the weight not placed yet, then placed
+	dtp.loadBits([]uint32{1000, 1000, 2000}, false)
+	td := &Device{
+		sck:               dtp,
+		dt:                dtp,
+		initialized:       true,
+		gain:              Gain128,
+		smoothingFactor:   1,
+		calibrationFactor: 1,
+	}
+	s, err := td.StartCalibration(100, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.Step(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.Step(); !errors.Is(err, ErrZeroFactor) || s.CurrentStep() != StepPlaceWeight {
+		t.Logf("expected %v and to stay in the step but got %v in step %d", ErrZeroFactor, err, s.CurrentStep())
+		t.FailNow()
+	}
+	if done, err := s.Step(); !done || err != nil {
+		t.Logf("expected the retry to finish the calibration but got %v", err)
+		t.FailNow()
+	}
+}
+
+func TestDevice_CalibrateInteractive(t *testing.T) {
+	dtp := &counterDataPin{}
+	// empty scale, then 500g on it
+	dtp.loadBits([]uint32{1000, 3000}, false)
+	td := &Device{
+		sck:               dtp,
+		dt:                dtp,
+		initialized:       true,
+		gain:              Gain128,
+		smoothingFactor:   1,
+		calibrationFactor: 1,
+	}
+	td.SetUnit("g")
+	if _, err := td.CalibrateInteractive(0); !errors.Is(err, ErrZeroWeight) {
+		t.Logf("expected %v but got %v", ErrZeroWeight, err)
+		t.FailNow()
+	}
+	s, err := td.CalibrateInteractive(500)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{"Remove all weight and press enter", "Place 500 g and press enter", "Calibration done"}
+	for i, prompt := range want {
+		if got := s.NextPrompt(); got != prompt {
+			t.Logf("step %d expected prompt %q but got %q", i, prompt, got)
+			t.FailNow()
+		}
+		if err := s.Advance(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if s.CurrentStep() != StepDone || td.GetCalibrationFactor() != 0.25 {
+		t.Logf("expected to be done with factor %f but got step %d and %f", 0.25, s.CurrentStep(), td.GetCalibrationFactor())
+		t.FailNow()
+	}
+
+	td.SetUnit("")
+	s, _ = td.CalibrateInteractive(2.5)
+	s.step = StepPlaceWeight
+	if got := s.NextPrompt(); got != "Place 2.5 and press enter" {
+		t.Logf("expected a prompt without unit but got %q", got)
+		t.FailNow()
+	}
+}